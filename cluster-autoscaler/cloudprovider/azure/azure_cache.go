@@ -58,6 +58,15 @@ type azureCache struct {
 	unownedInstances     map[azureRef]bool
 	autoscalingOptions   map[azureRef]map[string]string
 	skus                 map[string]*skewer.Cache
+
+	// reportedSkuDisagreements remembers the SKU fields for which a disagreement between
+	// the SKU API and the static list was already reported. It isn't reset on refresh.
+	reportedSkuDisagreements map[skuDisagreement]bool
+}
+
+type skuDisagreement struct {
+	sku   string
+	field string
 }
 
 func newAzureCache(client *azClient, cacheTTL time.Duration, resourceGroup, vmType string, enableDynamicInstanceList bool, defaultLocation string) (*azureCache, error) {
@@ -74,6 +83,8 @@ func newAzureCache(client *azClient, cacheTTL time.Duration, resourceGroup, vmTy
 		unownedInstances:     make(map[azureRef]bool),
 		autoscalingOptions:   make(map[azureRef]map[string]string),
 		skus:                 make(map[string]*skewer.Cache),
+
+		reportedSkuDisagreements: make(map[skuDisagreement]bool),
 	}
 
 	if enableDynamicInstanceList {
@@ -307,6 +318,20 @@ func (m *azureCache) GetSKU(ctx context.Context, skuName, location string) (skew
 	return cache.Get(ctx, skuName, skewer.VirtualMachines, location)
 }
 
+// markSkuDisagreementReported marks a disagreement on a field of a SKU as reported, and
+// returns whether it was already reported before.
+func (m *azureCache) markSkuDisagreementReported(sku, field string) bool {
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+
+	key := skuDisagreement{sku: sku, field: field}
+	if m.reportedSkuDisagreements[key] {
+		return true
+	}
+	m.reportedSkuDisagreements[key] = true
+	return false
+}
+
 func (m *azureCache) getRegisteredNodeGroups() []cloudprovider.NodeGroup {
 	m.mutex.Lock()
 	defer m.mutex.Unlock()
@@ -17,10 +17,12 @@ limitations under the License.
 package azure
 
 import (
+	"context"
 	"testing"
 
 	"k8s.io/autoscaler/cluster-autoscaler/cloudprovider"
 
+	skucompute "github.com/Azure/azure-sdk-for-go/services/compute/mgmt/2019-07-01/compute"
 	"github.com/stretchr/testify/assert"
 )
 
@@ -70,3 +72,15 @@ func TestFindForInstance(t *testing.T) {
 	assert.NoError(t, err)
 	assert.True(t, ac.unownedInstances[inst])
 }
+
+type fakeSkuClient struct {
+	skus []skucompute.ResourceSku
+}
+
+func (f *fakeSkuClient) ListComplete(ctx context.Context, filter string) (skucompute.ResourceSkusResultIterator, error) {
+	page := skucompute.NewResourceSkusResultPage(skucompute.ResourceSkusResult{Value: &f.skus},
+		func(context.Context, skucompute.ResourceSkusResult) (skucompute.ResourceSkusResult, error) {
+			return skucompute.ResourceSkusResult{}, nil
+		})
+	return skucompute.NewResourceSkusResultIterator(page), nil
+}
@@ -24,13 +24,15 @@ import (
 	"os"
 	"time"
 
-	"github.com/Azure/azure-sdk-for-go/services/compute/mgmt/2019-07-01/compute"
+	skucompute "github.com/Azure/azure-sdk-for-go/services/compute/mgmt/2019-07-01/compute"
+	"github.com/Azure/azure-sdk-for-go/services/compute/mgmt/2022-08-01/compute"
 	"github.com/Azure/azure-sdk-for-go/services/resources/mgmt/2017-05-10/resources"
 	"github.com/Azure/azure-sdk-for-go/services/storage/mgmt/2021-02-01/storage"
 	"github.com/Azure/go-autorest/autorest"
 	"github.com/Azure/go-autorest/autorest/adal"
 	"github.com/Azure/go-autorest/autorest/azure"
 	"github.com/Azure/go-autorest/autorest/azure/auth"
+	"github.com/Azure/skewer"
 
 	klog "k8s.io/klog/v2"
 
@@ -51,6 +53,11 @@ type DeploymentsClient interface {
 	Delete(ctx context.Context, resourceGroupName string, deploymentName string) (resp *http.Response, err error)
 }
 
+// RollingUpgradesClient defines needed functions for azure compute.VirtualMachineScaleSetRollingUpgradesClient.
+type RollingUpgradesClient interface {
+	GetLatest(ctx context.Context, resourceGroupName string, VMScaleSetName string) (result compute.RollingUpgradeStatusInfo, err error)
+}
+
 type azDeploymentsClient struct {
 	client resources.DeploymentsClient
 }
@@ -150,7 +157,8 @@ type azClient struct {
 	interfacesClient                interfaceclient.Interface
 	disksClient                     diskclient.Interface
 	storageAccountsClient           storageaccountclient.Interface
-	skuClient                       compute.ResourceSkusClient
+	skuClient                       skewer.ResourceClient
+	rollingUpgradesClient           RollingUpgradesClient
 }
 
 // newServicePrincipalTokenFromCredentials creates a new ServicePrincipalToken using values of the
@@ -274,10 +282,15 @@ func newAzClient(cfg *Config, env *azure.Environment) (*azClient, error) {
 
 	// Reference on why selecting ResourceManagerEndpoint as baseURI -
 	// https://github.com/Azure/go-autorest/blob/main/autorest/azure/environments.go
-	skuClient := compute.NewResourceSkusClientWithBaseURI(azClientConfig.ResourceManagerEndpoint, cfg.SubscriptionID)
+	skuClient := skucompute.NewResourceSkusClientWithBaseURI(azClientConfig.ResourceManagerEndpoint, cfg.SubscriptionID)
 	skuClient.Authorizer = azClientConfig.Authorizer
 	klog.V(5).Infof("Created sku client with authorizer: %v", skuClient)
 
+	rollingUpgradesClient := compute.NewVirtualMachineScaleSetRollingUpgradesClientWithBaseURI(azClientConfig.ResourceManagerEndpoint, cfg.SubscriptionID)
+	rollingUpgradesClient.Authorizer = azClientConfig.Authorizer
+	configureUserAgent(&rollingUpgradesClient.Client)
+	klog.V(5).Infof("Created rolling upgrades client with authorizer: %v", rollingUpgradesClient)
+
 	return &azClient{
 		disksClient:                     disksClient,
 		interfacesClient:                interfacesClient,
@@ -287,5 +300,6 @@ func newAzClient(cfg *Config, env *azure.Environment) (*azClient, error) {
 		virtualMachinesClient:           virtualMachinesClient,
 		storageAccountsClient:           storageAccountsClient,
 		skuClient:                       skuClient,
+		rollingUpgradesClient:           rollingUpgradesClient,
 	}, nil
 }
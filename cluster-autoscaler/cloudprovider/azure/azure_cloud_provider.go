@@ -83,9 +83,16 @@ func (azure *AzureCloudProvider) GetAvailableGPUTypes() map[string]struct{} {
 
 // GetNodeGpuConfig returns the label, type and resource name for the GPU added to node. If node doesn't have
 // any GPUs, it returns nil.
+// AMD GPU nodes expose the amd.com/gpu resource instead of nvidia.com/gpu.
 func (azure *AzureCloudProvider) GetNodeGpuConfig(node *apiv1.Node) *cloudprovider.GpuConfig {
-	return gpu.GetNodeGPUFromCloudProvider(azure, node)
-
+	gpuConfig := gpu.GetNodeGPUFromCloudProvider(azure, node)
+	if gpuConfig == nil {
+		return nil
+	}
+	if _, found := node.Status.Capacity[resourceAMDGPU]; found || gpuConfig.Type == gpuVendorAMD {
+		gpuConfig.ResourceName = resourceAMDGPU
+	}
+	return gpuConfig
 }
 
 // NodeGroups returns all node groups configured for this cloud provider.
@@ -191,5 +198,7 @@ func BuildAzure(opts config.AutoscalingOptions, do cloudprovider.NodeGroupDiscov
 	if err != nil {
 		klog.Fatalf("Failed to create Azure cloud provider: %v", err)
 	}
+	// Register Azure metrics.
+	RegisterMetrics()
 	return provider
 }
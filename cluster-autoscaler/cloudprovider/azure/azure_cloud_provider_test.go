@@ -24,7 +24,10 @@ import (
 	"github.com/Azure/go-autorest/autorest/to"
 
 	apiv1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/autoscaler/cluster-autoscaler/cloudprovider"
+	"k8s.io/autoscaler/cluster-autoscaler/utils/gpu"
 	"sigs.k8s.io/cloud-provider-azure/pkg/azureclients/vmclient/mockvmclient"
 	"sigs.k8s.io/cloud-provider-azure/pkg/azureclients/vmssclient/mockvmssclient"
 	"sigs.k8s.io/cloud-provider-azure/pkg/azureclients/vmssvmclient/mockvmssvmclient"
@@ -120,6 +123,56 @@ func TestNodeGroups(t *testing.T) {
 	assert.Equal(t, len(provider.NodeGroups()), 1)
 }
 
+func TestGetNodeGpuConfig(t *testing.T) {
+	provider := newTestProvider(t)
+
+	testCases := []struct {
+		name                 string
+		labels               map[string]string
+		capacity             apiv1.ResourceList
+		expectedResourceName apiv1.ResourceName
+	}{
+		{
+			name:                 "nvidia gpu node",
+			labels:               map[string]string{GPULabel: gpuVendorNvidia},
+			capacity:             apiv1.ResourceList{gpu.ResourceNvidiaGPU: *resource.NewQuantity(1, resource.DecimalSI)},
+			expectedResourceName: gpu.ResourceNvidiaGPU,
+		},
+		{
+			name:                 "amd gpu template node",
+			labels:               map[string]string{GPULabel: gpuVendorAMD},
+			expectedResourceName: resourceAMDGPU,
+		},
+		{
+			name:                 "amd gpu node with a generic accelerator label",
+			labels:               map[string]string{GPULabel: "true"},
+			capacity:             apiv1.ResourceList{resourceAMDGPU: *resource.NewQuantity(1, resource.DecimalSI)},
+			expectedResourceName: resourceAMDGPU,
+		},
+		{
+			name: "node without gpu",
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			node := &apiv1.Node{
+				ObjectMeta: metav1.ObjectMeta{Name: "node", Labels: tc.labels},
+				Status:     apiv1.NodeStatus{Capacity: tc.capacity},
+			}
+			gpuConfig := provider.GetNodeGpuConfig(node)
+			if tc.expectedResourceName == "" {
+				assert.Nil(t, gpuConfig)
+				return
+			}
+			assert.NotNil(t, gpuConfig)
+			assert.Equal(t, GPULabel, gpuConfig.Label)
+			assert.Equal(t, tc.labels[GPULabel], gpuConfig.Type)
+			assert.Equal(t, tc.expectedResourceName, gpuConfig.ResourceName)
+		})
+	}
+}
+
 func TestNodeGroupForNode(t *testing.T) {
 	ctrl := gomock.NewController(t)
 	defer ctrl.Finish()
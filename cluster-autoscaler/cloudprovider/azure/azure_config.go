@@ -120,6 +120,10 @@ type Config struct {
 	// Jitter in seconds subtracted from the VMSS cache TTL before the first refresh
 	VmssVmsCacheJitter int `json:"vmssVmsCacheJitter" yaml:"vmssVmsCacheJitter"`
 
+	// Maximum difference between the cached VMSS instance count and the VMSS capacity
+	// before the instances cache is refreshed ahead of its TTL, only applies for vmss type
+	InstanceCacheConsistencyThreshold int `json:"instanceCacheConsistencyThreshold" yaml:"instanceCacheConsistencyThreshold"`
+
 	// number of latest deployments that will not be deleted
 	MaxDeploymentsCount int64 `json:"maxDeploymentsCount" yaml:"maxDeploymentsCount"`
 
@@ -133,6 +137,21 @@ type Config struct {
 	// EnableDynamicInstanceList defines whether to enable dynamic instance workflow for instance information check
 	EnableDynamicInstanceList bool `json:"enableDynamicInstanceList,omitempty" yaml:"enableDynamicInstanceList,omitempty"`
 
+	// EnableDynamicInstanceListFallback defines whether SKUs missing from the static list are looked up with the SKU API
+	// when the dynamic instance workflow is disabled
+	EnableDynamicInstanceListFallback bool `json:"enableDynamicInstanceListFallback,omitempty" yaml:"enableDynamicInstanceListFallback,omitempty"`
+
+	// NodeLabelTagPrefix is an additional prefix of the VMSS tags setting labels on template nodes, besides
+	// k8s.io_cluster-autoscaler_node-template_label_
+	NodeLabelTagPrefix string `json:"nodeLabelTagPrefix,omitempty" yaml:"nodeLabelTagPrefix,omitempty"`
+
+	// FPGAResourceName is the name of the FPGA resource advertised by template nodes of NP-series SKUs,
+	// xilinx.com/fpga if empty
+	FPGAResourceName string `json:"fpgaResourceName,omitempty" yaml:"fpgaResourceName,omitempty"`
+
+	// InstanceTypesFile is the path of a JSON file adding or overriding SKUs of the static instance types list
+	InstanceTypesFile string `json:"instanceTypesFile,omitempty" yaml:"instanceTypesFile,omitempty"`
+
 	// EnableVmssFlex defines whether to enable Vmss Flex support or not
 	EnableVmssFlex bool `json:"enableVmssFlex,omitempty" yaml:"enableVmssFlex,omitempty"`
 }
@@ -222,6 +241,13 @@ func BuildAzureConfig(configReader io.Reader) (*Config, error) {
 			}
 		}
 
+		if threshold := os.Getenv("AZURE_INSTANCE_CACHE_CONSISTENCY_THRESHOLD"); threshold != "" {
+			cfg.InstanceCacheConsistencyThreshold, err = strconv.Atoi(threshold)
+			if err != nil {
+				return nil, fmt.Errorf("failed to parse AZURE_INSTANCE_CACHE_CONSISTENCY_THRESHOLD %q: %v", threshold, err)
+			}
+		}
+
 		if threshold := os.Getenv("AZURE_MAX_DEPLOYMENT_COUNT"); threshold != "" {
 			cfg.MaxDeploymentsCount, err = strconv.ParseInt(threshold, 10, 0)
 			if err != nil {
@@ -245,6 +271,17 @@ func BuildAzureConfig(configReader io.Reader) (*Config, error) {
 			cfg.EnableDynamicInstanceList = dynamicInstanceListDefault
 		}
 
+		if enableDynamicInstanceListFallback := os.Getenv("AZURE_ENABLE_DYNAMIC_INSTANCE_LIST_FALLBACK"); enableDynamicInstanceListFallback != "" {
+			cfg.EnableDynamicInstanceListFallback, err = strconv.ParseBool(enableDynamicInstanceListFallback)
+			if err != nil {
+				return nil, fmt.Errorf("failed to parse AZURE_ENABLE_DYNAMIC_INSTANCE_LIST_FALLBACK %q: %v", enableDynamicInstanceListFallback, err)
+			}
+		}
+
+		cfg.InstanceTypesFile = os.Getenv("AZURE_INSTANCE_TYPES_FILE")
+		cfg.NodeLabelTagPrefix = os.Getenv("AZURE_NODE_LABEL_TAG_PREFIX")
+		cfg.FPGAResourceName = os.Getenv("AZURE_FPGA_RESOURCE_NAME")
+
 		if enableVmssFlex := os.Getenv("AZURE_ENABLE_VMSS_FLEX"); enableVmssFlex != "" {
 			cfg.EnableVmssFlex, err = strconv.ParseBool(enableVmssFlex)
 			if err != nil {
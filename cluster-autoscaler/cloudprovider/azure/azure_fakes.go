@@ -113,6 +113,27 @@ func (m *DeploymentsClientMock) Delete(ctx context.Context, resourceGroupName, d
 	return
 }
 
+// RollingUpgradesClientMock mocks for RollingUpgradesClient.
+type RollingUpgradesClientMock struct {
+	mutex     sync.Mutex
+	calls     int
+	FakeStore map[string]compute.RollingUpgradeStatusInfo
+}
+
+// GetLatest gets the latest rolling upgrade status of the given scale set.
+func (m *RollingUpgradesClientMock) GetLatest(ctx context.Context, resourceGroupName string, VMScaleSetName string) (result compute.RollingUpgradeStatusInfo, err error) {
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+
+	m.calls++
+	status, ok := m.FakeStore[VMScaleSetName]
+	if !ok {
+		return result, fmt.Errorf("rolling upgrade not found")
+	}
+
+	return status, nil
+}
+
 func fakeVMSSWithTags(vmssName string, tags map[string]*string) compute.VirtualMachineScaleSet {
 	skuName := "Standard_D4_v2"
 	var vmssCapacity int64 = 3
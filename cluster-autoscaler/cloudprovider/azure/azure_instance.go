@@ -18,7 +18,9 @@ package azure
 
 import (
 	"context"
+	"encoding/json"
 	"fmt"
+	"os"
 	"regexp"
 	"strings"
 
@@ -30,6 +32,9 @@ import (
 // It is declared as a variable for testing purpose.
 var GetVMSSTypeStatically = func(template compute.VirtualMachineScaleSet) (*InstanceType, error) {
 	var vmssType *InstanceType
+	if template.Sku == nil || template.Sku.Name == nil {
+		return nil, fmt.Errorf("vmss has no SKU name")
+	}
 
 	for k := range InstanceTypes {
 		if strings.EqualFold(k, *template.Sku.Name) {
@@ -63,6 +68,9 @@ var GetVMSSTypeStatically = func(template compute.VirtualMachineScaleSet) (*Inst
 var GetVMSSTypeDynamically = func(template compute.VirtualMachineScaleSet, azCache *azureCache) (InstanceType, error) {
 	ctx := context.Background()
 	var vmssType InstanceType
+	if template.Sku == nil || template.Sku.Name == nil || template.Location == nil {
+		return vmssType, fmt.Errorf("vmss has no SKU name or location")
+	}
 
 	sku, err := azCache.GetSKU(ctx, *template.Sku.Name, *template.Location)
 	if err != nil {
@@ -99,3 +107,39 @@ var GetVMSSTypeDynamically = func(template compute.VirtualMachineScaleSet, azCac
 
 	return vmssType, nil
 }
+
+// loadInstanceTypesFile merges the instance types listed in a JSON file over the given instance types,
+// so SKUs missing from the static list can be added, or their information overridden, without a rebuild.
+// The file holds a list of objects with the InstanceType, VCPU, MemoryMb and GPU fields.
+func loadInstanceTypesFile(path string, instanceTypes map[string]*InstanceType) error {
+	body, err := os.ReadFile(path)
+	if err != nil {
+		return fmt.Errorf("failed to read instance types file %q: %v", path, err)
+	}
+	var fileInstanceTypes []InstanceType
+	if err := json.Unmarshal(body, &fileInstanceTypes); err != nil {
+		return fmt.Errorf("failed to unmarshal instance types file %q: %v", path, err)
+	}
+
+	for i := range fileInstanceTypes {
+		instanceType := fileInstanceTypes[i]
+		if instanceType.InstanceType == "" || instanceType.VCPU <= 0 || instanceType.MemoryMb <= 0 || instanceType.GPU < 0 {
+			return fmt.Errorf("invalid instance type %+v in %q: InstanceType, VCPU and MemoryMb are required, and GPU can't be negative", instanceType, path)
+		}
+		// SKU names are matched case-insensitively, so an override replaces the existing entry
+		key := instanceType.InstanceType
+		for existing := range instanceTypes {
+			if strings.EqualFold(existing, key) {
+				key = existing
+				break
+			}
+		}
+		if _, found := instanceTypes[key]; found {
+			klog.Infof("Overriding instance type %s with %+v from %s", key, instanceType, path)
+		} else {
+			klog.Infof("Adding instance type %s with %+v from %s", key, instanceType, path)
+		}
+		instanceTypes[key] = &instanceType
+	}
+	return nil
+}
@@ -19,6 +19,7 @@ package azure
 import (
 	"github.com/Azure/skewer"
 	"github.com/pkg/errors"
+	"regexp"
 	"strings"
 )
 
@@ -75,6 +76,66 @@ var (
 		"standard_nc48ads_a100_v4": true,
 		"standard_nc96ads_a100_v4": true,
 	}
+
+	// nvidiaGpuProducts maps the Nvidia SKU families, i.e. SKU names without their vCPU count (see skuFamily),
+	// to the gpu product label value the Nvidia GPU feature discovery sets on their nodes.
+	nvidiaGpuProducts = map[string]string{
+		"nc":             "Tesla-K80",
+		"ncr":            "Tesla-K80",
+		"nv":             "Tesla-M60",
+		"nvr":            "Tesla-M60",
+		"nvs_v3":         "Tesla-M60",
+		"nds":            "Tesla-P40",
+		"ndrs":           "Tesla-P40",
+		"ncs_v2":         "Tesla-P100-PCIE-16GB",
+		"ncrs_v2":        "Tesla-P100-PCIE-16GB",
+		"ncs_v3":         "Tesla-V100-PCIE-16GB",
+		"ncrs_v3":        "Tesla-V100-PCIE-16GB",
+		"nds_v3":         "Tesla-V100-SXM2-32GB",
+		"ndrs_v2":        "Tesla-V100-SXM2-32GB",
+		"ncas_t4_v3":     "Tesla-T4",
+		"ndasr_v4":       "NVIDIA-A100-SXM4-40GB",
+		"ndasr_a100_v4":  "NVIDIA-A100-SXM4-40GB",
+		"ndamsr_a100_v4": "NVIDIA-A100-SXM4-80GB",
+		"ncads_a100_v4":  "NVIDIA-A100-80GB-PCIe",
+	}
+
+	// skuFamilyRe matches the SKU names, capturing the series (e.g. nc), the additive features (e.g. ads) and the rest
+	// of the name (e.g. _a100_v4) around the vCPU count.
+	skuFamilyRe = regexp.MustCompile(`^standard_([a-z]+)[0-9]+([a-z]*)(_.*)?$`)
+
+	// AMDEnabledSKUs represents a list of AMD gpus.
+	AMDEnabledSKUs = map[string]bool{
+		// Radeon Instinct MI25
+		"standard_nv4as_v4":  true,
+		"standard_nv8as_v4":  true,
+		"standard_nv16as_v4": true,
+		"standard_nv32as_v4": true,
+		// Radeon PRO V620
+		"standard_ng8ads_v620_v1":   true,
+		"standard_ng16ads_v620_v1":  true,
+		"standard_ng32ads_v620_v1":  true,
+		"standard_ng32adms_v620_v1": true,
+		// Instinct MI300X
+		"standard_nd96isr_mi300x_v5": true,
+	}
+)
+
+const (
+	// gpuVendorNvidia is the accelerator label value of nodes with Nvidia gpus.
+	gpuVendorNvidia = "nvidia"
+	// gpuVendorAMD is the accelerator label value of nodes with AMD gpus.
+	gpuVendorAMD = "amd"
+	// gpuVendorNone is returned for SKUs without a known gpu vendor.
+	gpuVendorNone = ""
+
+	// resourceAMDGPU is the name of the AMD GPU resource.
+	resourceAMDGPU = "amd.com/gpu"
+	// defaultFPGAResourceName is the name of the FPGA resource of NP-series nodes, unless configured otherwise.
+	defaultFPGAResourceName = "xilinx.com/fpga"
+
+	// nvidiaGpuProductLabel is the label set by the Nvidia GPU feature discovery with the gpu model of the node.
+	nvidiaGpuProductLabel = "nvidia.com/gpu.product"
 )
 
 // isNvidiaEnabledSKU determines if an VM SKU has nvidia driver support.
@@ -85,6 +146,46 @@ func isNvidiaEnabledSKU(vmSize string) bool {
 	return NvidiaEnabledSKUs[vmSize]
 }
 
+// isAMDEnabledSKU determines if an VM SKU has AMD gpus.
+func isAMDEnabledSKU(vmSize string) bool {
+	vmSize = strings.ToLower(vmSize)
+	vmSize = strings.TrimSuffix(vmSize, "_promo")
+	return AMDEnabledSKUs[vmSize]
+}
+
+// getGpuVendorFromSku returns the gpu vendor of a VM SKU, or gpuVendorNone if it
+// has no known gpus. NP-series SKUs have FPGAs rather than gpus.
+func getGpuVendorFromSku(vmSize string) string {
+	switch {
+	case isNPSeries(vmSize):
+		return gpuVendorNone
+	case isNvidiaEnabledSKU(vmSize):
+		return gpuVendorNvidia
+	case isAMDEnabledSKU(vmSize):
+		return gpuVendorAMD
+	default:
+		return gpuVendorNone
+	}
+}
+
+// skuFamily returns the family of a VM SKU, i.e. its lowercased name without the Standard_ prefix, its vCPU
+// count and the optional _Promo suffix (e.g. ncads_a100_v4 for Standard_NC24ads_A100_v4), or "" if it isn't
+// a well-formed SKU name.
+func skuFamily(vmSize string) string {
+	vmSize = strings.TrimSuffix(strings.ToLower(vmSize), "_promo")
+	matches := skuFamilyRe.FindStringSubmatch(vmSize)
+	if matches == nil {
+		return ""
+	}
+	return matches[1] + matches[2] + matches[3]
+}
+
+// getNvidiaGpuProductFromSku returns the gpu product label value of the nodes of an Nvidia SKU, or "" if its
+// family isn't known.
+func getNvidiaGpuProductFromSku(vmSize string) string {
+	return nvidiaGpuProducts[skuFamily(vmSize)]
+}
+
 // getGpuFromSku extracts gpu information from vmss sku.
 func getGpuFromSku(sku skewer.SKU) (int64, error) {
 	errCapabilityValueNil := &skewer.ErrCapabilityValueNil{}
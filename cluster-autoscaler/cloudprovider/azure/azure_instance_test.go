@@ -0,0 +1,68 @@
+/*
+Copyright 2023 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package azure
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestLoadInstanceTypesFile(t *testing.T) {
+	writeFile := func(t *testing.T, content string) string {
+		path := filepath.Join(t.TempDir(), "instance-types.json")
+		assert.NoError(t, os.WriteFile(path, []byte(content), 0644))
+		return path
+	}
+	newInstanceTypes := func() map[string]*InstanceType {
+		return map[string]*InstanceType{
+			"Standard_D2s_v3": {InstanceType: "Standard_D2s_v3", VCPU: 2, MemoryMb: 8192},
+		}
+	}
+
+	t.Run("adds and overrides instance types", func(t *testing.T) {
+		instanceTypes := newInstanceTypes()
+		path := writeFile(t, `[
+			{"InstanceType": "Standard_New_v9", "VCPU": 16, "MemoryMb": 65536, "GPU": 2},
+			{"InstanceType": "standard_d2s_v3", "VCPU": 2, "MemoryMb": 4096}
+		]`)
+
+		assert.NoError(t, loadInstanceTypesFile(path, instanceTypes))
+		assert.Len(t, instanceTypes, 2)
+		assert.Equal(t, &InstanceType{InstanceType: "Standard_New_v9", VCPU: 16, MemoryMb: 65536, GPU: 2}, instanceTypes["Standard_New_v9"])
+		assert.Equal(t, int64(4096), instanceTypes["Standard_D2s_v3"].MemoryMb)
+	})
+
+	t.Run("invalid instance type", func(t *testing.T) {
+		instanceTypes := newInstanceTypes()
+		path := writeFile(t, `[{"InstanceType": "Standard_New_v9", "VCPU": 16}]`)
+
+		assert.Error(t, loadInstanceTypesFile(path, instanceTypes))
+	})
+
+	t.Run("malformed file", func(t *testing.T) {
+		path := writeFile(t, `{"InstanceType": "Standard_New_v9"}`)
+
+		assert.Error(t, loadInstanceTypesFile(path, newInstanceTypes()))
+	})
+
+	t.Run("missing file", func(t *testing.T) {
+		assert.Error(t, loadInstanceTypesFile(filepath.Join(t.TempDir(), "missing.json"), newInstanceTypes()))
+	})
+}
@@ -71,6 +71,12 @@ func createAzureManagerInternal(configReader io.Reader, discoveryOpts cloudprovi
 
 	klog.Infof("Starting azure manager with subscription ID %q", cfg.SubscriptionID)
 
+	if cfg.InstanceTypesFile != "" {
+		if err := loadInstanceTypesFile(cfg.InstanceTypesFile, InstanceTypes); err != nil {
+			return nil, err
+		}
+	}
+
 	if azClient == nil {
 		azClient, err = newAzClient(cfg, &env)
 		if err != nil {
@@ -263,6 +269,34 @@ func (m *AzureManager) GetScaleSetOptions(scaleSetName string, defaults config.N
 	if opt, ok := getDurationOption(options, scaleSetName, config.DefaultScaleDownUnreadyTimeKey); ok {
 		defaults.ScaleDownUnreadyTime = opt
 	}
+	if opt, ok := getDurationOption(options, scaleSetName, config.DefaultMaxNodeProvisionTimeKey); ok {
+		defaults.MaxNodeProvisionTime = opt
+	}
+	if opt, ok := getIntOption(options, scaleSetName, config.DefaultMaxDrainParallelismKey); ok {
+		if opt > 0 {
+			defaults.MaxDrainParallelism = opt
+		} else {
+			klog.Warningf("ignoring VMSS %q tag %s_%s value %d: must be a positive number",
+				scaleSetName, nodeOptionsTagName, config.DefaultMaxDrainParallelismKey, opt)
+		}
+	}
+	if opt, ok := getIntOption(options, scaleSetName, config.DefaultMaxIdleNodesKey); ok {
+		if opt > 0 {
+			defaults.MaxIdleNodes = opt
+		} else {
+			klog.Warningf("ignoring VMSS %q tag %s_%s value %d: must be a positive number",
+				scaleSetName, nodeOptionsTagName, config.DefaultMaxIdleNodesKey, opt)
+		}
+	}
+	if opt, ok := getBoolOption(options, scaleSetName, config.DefaultExcludeFromBalancingKey); ok {
+		defaults.ExcludeFromBalancing = opt
+	}
+	if opt, ok := getBoolOption(options, scaleSetName, config.DefaultExcludeTolerateAllPodsKey); ok {
+		defaults.ExcludeTolerateAllPods = opt
+	}
+	if opt, ok := getBoolOption(options, scaleSetName, config.DefaultScaleDownDisabledKey); ok {
+		defaults.ScaleDownDisabled = opt
+	}
 
 	return &defaults
 }
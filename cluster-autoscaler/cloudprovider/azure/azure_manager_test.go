@@ -682,6 +682,7 @@ func TestGetFilteredAutoscalingGroupsVmss(t *testing.T) {
 		curSize:                3,
 		sizeRefreshPeriod:      manager.azureCache.refreshInterval,
 		instancesRefreshPeriod: defaultVmssInstancesRefreshPeriod,
+		instancesRefreshSpread: instancesRefreshSpread(vmssName, defaultVmssInstancesRefreshPeriod),
 	}}
 	assert.True(t, assert.ObjectsAreEqualValues(expectedAsgs, asgs), "expected %#v, but found: %#v", expectedAsgs, asgs)
 }
@@ -782,6 +783,7 @@ func TestGetScaleSetOptions(t *testing.T) {
 		ScaleDownGpuUtilizationThreshold: 0.2,
 		ScaleDownUnneededTime:            time.Second,
 		ScaleDownUnreadyTime:             time.Minute,
+		MaxNodeProvisionTime:             15 * time.Minute,
 	}
 
 	tags := map[string]string{
@@ -789,6 +791,12 @@ func TestGetScaleSetOptions(t *testing.T) {
 		config.DefaultScaleDownGpuUtilizationThresholdKey: "0.3",
 		config.DefaultScaleDownUnneededTimeKey:            "30m",
 		config.DefaultScaleDownUnreadyTimeKey:             "1h",
+		config.DefaultMaxNodeProvisionTimeKey:             "25m",
+		config.DefaultMaxDrainParallelismKey:              "3",
+		config.DefaultMaxIdleNodesKey:                     "2",
+		config.DefaultExcludeFromBalancingKey:             "true",
+		config.DefaultExcludeTolerateAllPodsKey:           "true",
+		config.DefaultScaleDownDisabledKey:                "true",
 	}
 	manager.azureCache.autoscalingOptions[azureRef{Name: "test1"}] = tags
 	opts := manager.GetScaleSetOptions("test1", defaultOptions)
@@ -796,12 +804,24 @@ func TestGetScaleSetOptions(t *testing.T) {
 	assert.Equal(t, opts.ScaleDownGpuUtilizationThreshold, 0.3)
 	assert.Equal(t, opts.ScaleDownUnneededTime, 30*time.Minute)
 	assert.Equal(t, opts.ScaleDownUnreadyTime, time.Hour)
+	assert.Equal(t, opts.MaxNodeProvisionTime, 25*time.Minute)
+	assert.Equal(t, opts.MaxDrainParallelism, 3)
+	assert.Equal(t, opts.MaxIdleNodes, 2)
+	assert.True(t, opts.ExcludeFromBalancing)
+	assert.True(t, opts.ExcludeTolerateAllPods)
+	assert.True(t, opts.ScaleDownDisabled)
 
 	tags = map[string]string{
 		//config.DefaultScaleDownUtilizationThresholdKey: ... // not specified (-> default)
 		config.DefaultScaleDownGpuUtilizationThresholdKey: "not-a-float",
 		config.DefaultScaleDownUnneededTimeKey:            "1m",
 		config.DefaultScaleDownUnreadyTimeKey:             "not-a-duration",
+		config.DefaultMaxNodeProvisionTimeKey:             "not-a-duration",
+		config.DefaultMaxDrainParallelismKey:              "0",
+		config.DefaultMaxIdleNodesKey:                     "-1",
+		config.DefaultExcludeFromBalancingKey:             "not-a-bool",
+		config.DefaultExcludeTolerateAllPodsKey:           "not-a-bool",
+		config.DefaultScaleDownDisabledKey:                "not-a-bool",
 	}
 	manager.azureCache.autoscalingOptions[azureRef{Name: "test2"}] = tags
 	opts = manager.GetScaleSetOptions("test2", defaultOptions)
@@ -809,6 +829,12 @@ func TestGetScaleSetOptions(t *testing.T) {
 	assert.Equal(t, opts.ScaleDownGpuUtilizationThreshold, defaultOptions.ScaleDownGpuUtilizationThreshold)
 	assert.Equal(t, opts.ScaleDownUnneededTime, time.Minute)
 	assert.Equal(t, opts.ScaleDownUnreadyTime, defaultOptions.ScaleDownUnreadyTime)
+	assert.Equal(t, opts.MaxNodeProvisionTime, defaultOptions.MaxNodeProvisionTime)
+	assert.Equal(t, opts.MaxDrainParallelism, defaultOptions.MaxDrainParallelism)
+	assert.Equal(t, opts.MaxIdleNodes, defaultOptions.MaxIdleNodes)
+	assert.Equal(t, opts.ExcludeFromBalancing, defaultOptions.ExcludeFromBalancing)
+	assert.Equal(t, opts.ExcludeTolerateAllPods, defaultOptions.ExcludeTolerateAllPods)
+	assert.Equal(t, opts.ScaleDownDisabled, defaultOptions.ScaleDownDisabled)
 
 	manager.azureCache.autoscalingOptions[azureRef{Name: "test3"}] = map[string]string{}
 	opts = manager.GetScaleSetOptions("test3", defaultOptions)
@@ -0,0 +1,119 @@
+/*
+Copyright 2023 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package azure
+
+import (
+	"time"
+
+	k8smetrics "k8s.io/component-base/metrics"
+	"k8s.io/component-base/metrics/legacyregistry"
+)
+
+const (
+	caNamespace = "cluster_autoscaler"
+
+	scaleActivityUp   = "up"
+	scaleActivityDown = "down"
+
+	invalidResourceTagName     = "name"
+	invalidResourceTagQuantity = "quantity"
+)
+
+var (
+	/**** Metrics related to the VMSS instance cache ****/
+	instanceCacheInconsistencyCounter = k8smetrics.NewCounterVec(
+		&k8smetrics.CounterOpts{
+			Namespace: caNamespace,
+			Name:      "azure_instance_cache_inconsistency_count",
+			Help:      "Counter of VMSS instance cache refreshes forced by the cached instance count diverging from the VMSS capacity.",
+		}, []string{"node_group"},
+	)
+
+	/**** Metrics related to VMSS scaling ****/
+	lastScaleActivityGauge = k8smetrics.NewGaugeVec(
+		&k8smetrics.GaugeOpts{
+			Namespace: caNamespace,
+			Name:      "azure_node_group_last_scale_activity_timestamp",
+			Help:      "Unix timestamp of the last successful scale up or scale down request of a VMSS.",
+		}, []string{"node_group", "direction"},
+	)
+
+	/**** Metrics related to template nodes ****/
+	templatePodsCapacityGauge = k8smetrics.NewGaugeVec(
+		&k8smetrics.GaugeOpts{
+			Namespace: caNamespace,
+			Name:      "azure_template_pods_capacity",
+			Help:      "Pods capacity of the template node of a VMSS, by the source which determined it.",
+		}, []string{"node_group", "source"},
+	)
+
+	invalidResourceTagCounter = k8smetrics.NewCounterVec(
+		&k8smetrics.CounterOpts{
+			Namespace: caNamespace,
+			Name:      "azure_invalid_resource_tag_count",
+			Help:      "Counter of VMSS resource tags ignored when building template nodes, by whether the resource name or quantity is invalid.",
+		}, []string{"reason"},
+	)
+
+	/**** Metrics related to SKU information ****/
+	skuDisagreementCounter = k8smetrics.NewCounterVec(
+		&k8smetrics.CounterOpts{
+			Namespace: caNamespace,
+			Name:      "azure_sku_disagreement_count",
+			Help:      "Counter of SKU fields for which the SKU API and the static SKU list disagree.",
+		}, []string{"sku", "field"},
+	)
+)
+
+// RegisterMetrics registers all Azure metrics.
+func RegisterMetrics() {
+	legacyregistry.MustRegister(instanceCacheInconsistencyCounter)
+	legacyregistry.MustRegister(lastScaleActivityGauge)
+	legacyregistry.MustRegister(skuDisagreementCounter)
+	legacyregistry.MustRegister(templatePodsCapacityGauge)
+	legacyregistry.MustRegister(invalidResourceTagCounter)
+}
+
+// registerInstanceCacheInconsistency registers a forced refresh of the instance cache of a VMSS.
+func registerInstanceCacheInconsistency(nodeGroup string) {
+	instanceCacheInconsistencyCounter.WithLabelValues(nodeGroup).Add(1.0)
+}
+
+// registerScaleActivity registers a successful scale request of a VMSS in the given direction.
+func registerScaleActivity(nodeGroup string, direction string, now time.Time) {
+	lastScaleActivityGauge.WithLabelValues(nodeGroup, direction).Set(float64(now.Unix()))
+}
+
+// registerSkuDisagreement registers a disagreement between the SKU API and the static SKU list.
+func registerSkuDisagreement(sku string, field string) {
+	skuDisagreementCounter.WithLabelValues(sku, field).Add(1.0)
+}
+
+// registerTemplatePodsCapacity registers the pods capacity of the template node of a VMSS and its source.
+func registerTemplatePodsCapacity(nodeGroup string, source string, podsCapacity int64) {
+	for _, otherSource := range []string{podsCapacitySourceTag, podsCapacitySourceOSDefault, podsCapacitySourceNetworkPlugin, podsCapacitySourceFallback} {
+		if otherSource != source {
+			templatePodsCapacityGauge.Delete(map[string]string{"node_group": nodeGroup, "source": otherSource})
+		}
+	}
+	templatePodsCapacityGauge.WithLabelValues(nodeGroup, source).Set(float64(podsCapacity))
+}
+
+// registerInvalidResourceTag registers a resource tag ignored for the given reason.
+func registerInvalidResourceTag(reason string) {
+	invalidResourceTagCounter.WithLabelValues(reason).Add(1.0)
+}
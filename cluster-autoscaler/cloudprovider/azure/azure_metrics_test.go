@@ -0,0 +1,102 @@
+/*
+Copyright 2023 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package azure
+
+import (
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"k8s.io/component-base/metrics/testutil"
+)
+
+var (
+	registerTestMetricsOnce sync.Once
+	testMetricsRegistry     = testutil.NewFakeKubeRegistry("1.28.0")
+)
+
+// registerTestMetrics registers the metrics read by tests in a test registry, as they're
+// only recorded once registered.
+func registerTestMetrics() {
+	registerTestMetricsOnce.Do(func() {
+		testMetricsRegistry.MustRegister(lastScaleActivityGauge, invalidResourceTagCounter, templatePodsCapacityGauge)
+	})
+}
+
+// lastScaleActivity returns the last scale activity timestamp of a VMSS in the given direction.
+func lastScaleActivity(t *testing.T, nodeGroup string, direction string) float64 {
+	registerTestMetrics()
+	value, err := testutil.GetGaugeMetricValue(lastScaleActivityGauge.WithLabelValues(nodeGroup, direction))
+	assert.NoError(t, err)
+	return value
+}
+
+func TestRegisterScaleActivity(t *testing.T) {
+	now := time.Now()
+	assert.Zero(t, lastScaleActivity(t, "test-scale-activity", scaleActivityUp))
+
+	registerScaleActivity("test-scale-activity", scaleActivityUp, now)
+	assert.Equal(t, float64(now.Unix()), lastScaleActivity(t, "test-scale-activity", scaleActivityUp))
+	assert.Zero(t, lastScaleActivity(t, "test-scale-activity", scaleActivityDown))
+
+	registerScaleActivity("test-scale-activity", scaleActivityDown, now.Add(time.Minute))
+	assert.Equal(t, float64(now.Unix()), lastScaleActivity(t, "test-scale-activity", scaleActivityUp))
+	assert.Equal(t, float64(now.Add(time.Minute).Unix()), lastScaleActivity(t, "test-scale-activity", scaleActivityDown))
+}
+
+// invalidResourceTags returns the number of resource tags ignored for the given reason.
+func invalidResourceTags(t *testing.T, reason string) float64 {
+	registerTestMetrics()
+	value, err := testutil.GetCounterMetricValue(invalidResourceTagCounter.WithLabelValues(reason))
+	assert.NoError(t, err)
+	return value
+}
+
+// templatePodsCapacities returns the template pods capacity of a VMSS, by source.
+func templatePodsCapacities(t *testing.T, nodeGroup string) map[string]float64 {
+	registerTestMetrics()
+	families, err := testMetricsRegistry.Gather()
+	assert.NoError(t, err)
+	capacities := make(map[string]float64)
+	for _, family := range families {
+		if family.GetName() != caNamespace+"_azure_template_pods_capacity" {
+			continue
+		}
+		for _, metric := range family.GetMetric() {
+			if !testutil.LabelsMatch(metric, map[string]string{"node_group": nodeGroup}) {
+				continue
+			}
+			for _, label := range metric.GetLabel() {
+				if label.GetName() == "source" {
+					capacities[label.GetValue()] = metric.GetGauge().GetValue()
+				}
+			}
+		}
+	}
+	return capacities
+}
+
+func TestRegisterTemplatePodsCapacity(t *testing.T) {
+	registerTemplatePodsCapacity("test-pods-capacity", podsCapacitySourceNetworkPlugin, 30)
+	assert.Equal(t, map[string]float64{podsCapacitySourceNetworkPlugin: 30}, templatePodsCapacities(t, "test-pods-capacity"))
+
+	for _, source := range []string{podsCapacitySourceTag, podsCapacitySourceFallback, podsCapacitySourceOSDefault, podsCapacitySourceNetworkPlugin} {
+		registerTemplatePodsCapacity("test-pods-capacity", source, 50)
+		assert.Equal(t, map[string]float64{source: 50}, templatePodsCapacities(t, "test-pods-capacity"))
+	}
+}
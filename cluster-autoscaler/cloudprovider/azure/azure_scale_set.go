@@ -18,6 +18,7 @@ package azure
 
 import (
 	"fmt"
+	"hash/fnv"
 	"math/rand"
 	"strings"
 	"sync"
@@ -39,6 +40,12 @@ var (
 	defaultVmssInstancesRefreshPeriod = 5 * time.Minute
 	vmssContextTimeout                = 3 * time.Minute
 	vmssSizeMutex                     sync.Mutex
+	// maxInstancesRefreshBackoffFactor caps how many times the instances refresh period is
+	// lengthened after consecutive throttled refreshes.
+	maxInstancesRefreshBackoffFactor = 8
+	// instancesRefreshSpreadFraction is the maximum fraction of the instances refresh period added to it
+	// for each scale set, so the instances caches of the scale sets don't all expire at once.
+	instancesRefreshSpreadFraction = 0.1
 )
 
 const (
@@ -68,10 +75,26 @@ type ScaleSet struct {
 
 	instancesRefreshPeriod time.Duration
 	instancesRefreshJitter int
+	// instancesRefreshSpread is added to the instances refresh period of this scale set, to stagger the
+	// instances cache expirations of the scale sets refreshed together.
+	instancesRefreshSpread time.Duration
+	// instancesConsistencyThreshold is the maximum difference between the cached instance
+	// count and the VMSS capacity before the instances cache is refreshed ahead of its TTL.
+	instancesConsistencyThreshold int
 
 	instanceMutex       sync.Mutex
 	instanceCache       []cloudprovider.Instance
 	lastInstanceRefresh time.Time
+	// throttledInstanceRefreshes counts the consecutive throttled instances refreshes. The cache
+	// isn't refreshed again before its TTL, doubled for each throttled refresh after the first.
+	throttledInstanceRefreshes int
+	// nextAllowedRefresh is the Retry-After hint of the last throttled instances refresh, before
+	// which the instances cache isn't refreshed.
+	nextAllowedRefresh time.Time
+
+	upgradeStatusMutex       sync.Mutex
+	osUpgradeInProgress      bool
+	lastUpgradeStatusRefresh time.Time
 }
 
 // NewScaleSet creates a new NewScaleSet.
@@ -87,6 +110,8 @@ func NewScaleSet(spec *dynamic.NodeGroupSpec, az *AzureManager, curSize int64) (
 		sizeRefreshPeriod:         az.azureCache.refreshInterval,
 		enableDynamicInstanceList: az.config.EnableDynamicInstanceList,
 		instancesRefreshJitter:    az.config.VmssVmsCacheJitter,
+
+		instancesConsistencyThreshold: az.config.InstanceCacheConsistencyThreshold,
 	}
 
 	if az.config.VmssVmsCacheTTL != 0 {
@@ -94,6 +119,16 @@ func NewScaleSet(spec *dynamic.NodeGroupSpec, az *AzureManager, curSize int64) (
 	} else {
 		scaleSet.instancesRefreshPeriod = defaultVmssInstancesRefreshPeriod
 	}
+	options := az.azureCache.getAutoscalingOptions(azureRef{Name: spec.Name})
+	if opt, ok := getDurationOption(options, spec.Name, instancesRefreshPeriodOptionKey); ok {
+		if opt > 0 {
+			scaleSet.instancesRefreshPeriod = opt
+		} else {
+			klog.Warningf("ignoring VMSS %q tag %s_%s value %s: must be a positive duration",
+				spec.Name, nodeOptionsTagName, instancesRefreshPeriodOptionKey, opt)
+		}
+	}
+	scaleSet.instancesRefreshSpread = instancesRefreshSpread(spec.Name, scaleSet.instancesRefreshPeriod)
 
 	return scaleSet, nil
 }
@@ -135,6 +170,60 @@ func (scaleSet *ScaleSet) GetOptions(defaults config.NodeGroupAutoscalingOptions
 	return scaleSet.manager.GetScaleSetOptions(*template.Name, defaults), nil
 }
 
+// ScaleDownDeferred returns true while an automatic OS image upgrade is in progress on the scale set,
+// as deleting instances that are being reimaged conflicts with and slows down the upgrade.
+func (scaleSet *ScaleSet) ScaleDownDeferred() (bool, string) {
+	template, err := scaleSet.getVMSSFromCache()
+	if err != nil {
+		return false, ""
+	}
+	if !isAutomaticOSUpgradeEnabled(template) {
+		return false, ""
+	}
+	if scaleSet.isOSUpgradeInProgress() {
+		return true, "automatic OS upgrade in progress"
+	}
+	return false, ""
+}
+
+func isAutomaticOSUpgradeEnabled(template compute.VirtualMachineScaleSet) bool {
+	if template.VirtualMachineScaleSetProperties == nil {
+		return false
+	}
+	upgradePolicy := template.UpgradePolicy
+	return upgradePolicy != nil && upgradePolicy.AutomaticOSUpgradePolicy != nil &&
+		upgradePolicy.AutomaticOSUpgradePolicy.EnableAutomaticOSUpgrade != nil &&
+		*upgradePolicy.AutomaticOSUpgradePolicy.EnableAutomaticOSUpgrade
+}
+
+// isOSUpgradeInProgress checks whether the latest rolling upgrade of the scale set is still rolling forward.
+// The VMSS provisioning state can't be used for this, as any model update, including our own scale ups,
+// puts the scale set in the Updating state. The result is cached for the size refresh period.
+func (scaleSet *ScaleSet) isOSUpgradeInProgress() bool {
+	scaleSet.upgradeStatusMutex.Lock()
+	defer scaleSet.upgradeStatusMutex.Unlock()
+
+	if scaleSet.lastUpgradeStatusRefresh.Add(scaleSet.sizeRefreshPeriod).After(time.Now()) {
+		return scaleSet.osUpgradeInProgress
+	}
+
+	ctx, cancel := getContextWithCancel()
+	defer cancel()
+
+	scaleSet.lastUpgradeStatusRefresh = time.Now()
+	status, err := scaleSet.manager.azClient.rollingUpgradesClient.GetLatest(ctx, scaleSet.manager.config.ResourceGroup, scaleSet.Name)
+	if err != nil {
+		// The API returns an error if the scale set has never been upgraded.
+		klog.V(4).Infof("Failed to get the latest rolling upgrade of scale set %s: %v", scaleSet.Name, err)
+		scaleSet.osUpgradeInProgress = false
+		return false
+	}
+	scaleSet.osUpgradeInProgress = status.RollingUpgradeStatusInfoProperties != nil &&
+		status.RunningStatus != nil &&
+		status.RunningStatus.Code == compute.RollingUpgradeStatusCodeRollingForward
+	return scaleSet.osUpgradeInProgress
+}
+
 // MaxSize returns maximum size of the node group.
 func (scaleSet *ScaleSet) MaxSize() int {
 	return scaleSet.maxSize
@@ -294,7 +383,21 @@ func (scaleSet *ScaleSet) IncreaseSize(delta int) error {
 		return fmt.Errorf("size increase too large - desired:%d max:%d", int(size)+delta, scaleSet.MaxSize())
 	}
 
-	return scaleSet.SetScaleSetSize(size + int64(delta))
+	if size == 0 {
+		template, err := scaleSet.getVMSSFromCache()
+		if err != nil {
+			return err
+		}
+		if isScaleFromZeroDisabled(template.Tags) {
+			return fmt.Errorf("scale from zero is disabled for the scale set %s", scaleSet.Name)
+		}
+	}
+
+	if err := scaleSet.SetScaleSetSize(size + int64(delta)); err != nil {
+		return err
+	}
+	registerScaleActivity(scaleSet.Name, scaleActivityUp, time.Now())
+	return nil
 }
 
 // GetScaleSetVms returns list of nodes for the given scale set.
@@ -439,6 +542,7 @@ func (scaleSet *ScaleSet) DeleteInstances(instances []*azureRef, hasUnregistered
 		klog.Errorf("virtualMachineScaleSetsClient.DeleteInstancesAsync for instances %v failed: %v", requiredIds.InstanceIds, rerr)
 		return rerr.Error()
 	}
+	registerScaleActivity(commonAsg.Id(), scaleActivityDown, time.Now())
 
 	// Proactively decrement scale set size so that we don't
 	// go below minimum node count if cache data is stale
@@ -451,9 +555,11 @@ func (scaleSet *ScaleSet) DeleteInstances(instances []*azureRef, hasUnregistered
 	}
 
 	// Proactively set the status of the instances to be deleted in cache
+	deletingStatuses := make(map[string]cloudprovider.InstanceStatus, len(instancesToDelete))
 	for _, instance := range instancesToDelete {
-		scaleSet.setInstanceStatusByProviderID(instance.Name, cloudprovider.InstanceStatus{State: cloudprovider.InstanceDeleting})
+		deletingStatuses[instance.Name] = cloudprovider.InstanceStatus{State: cloudprovider.InstanceDeleting}
 	}
+	scaleSet.setInstanceStatusesByProviderIDs(deletingStatuses)
 
 	go scaleSet.waitForDeleteInstances(future, requiredIds)
 
@@ -513,6 +619,13 @@ func (scaleSet *ScaleSet) TemplateNodeInfo() (*schedulerframework.NodeInfo, erro
 		return nil, err
 	}
 
+	// Without a template node the scale set can't be scaled up from zero,
+	// while scale-ups from existing nodes and scale-downs are unaffected.
+	if isScaleFromZeroDisabled(template.Tags) {
+		klog.V(4).Infof("Scale from zero is disabled for the scale set %s, not building a template node", scaleSet.Name)
+		return nil, cloudprovider.ErrNotImplemented
+	}
+
 	node, err := buildNodeFromTemplate(scaleSet.Name, template, scaleSet.manager)
 	if err != nil {
 		return nil, err
@@ -535,12 +648,21 @@ func (scaleSet *ScaleSet) Nodes() ([]cloudprovider.Instance, error) {
 	scaleSet.instanceMutex.Lock()
 	defer scaleSet.instanceMutex.Unlock()
 
-	if int64(len(scaleSet.instanceCache)) == curSize &&
-		scaleSet.lastInstanceRefresh.Add(scaleSet.instancesRefreshPeriod).After(time.Now()) {
-		klog.V(4).Infof("Nodes: returns with curSize %d", curSize)
+	if time.Now().Before(scaleSet.nextAllowedRefresh) {
+		klog.V(4).Infof("Nodes: instances refresh for vmss %q deferred until %v, returns cached instances", scaleSet.Name, scaleSet.nextAllowedRefresh)
 		return scaleSet.instanceCache, nil
 	}
 
+	if scaleSet.nextInstanceRefresh().After(time.Now()) {
+		if scaleSet.isInstanceCacheConsistent(curSize) || scaleSet.throttledInstanceRefreshes > 0 {
+			klog.V(4).Infof("Nodes: returns with curSize %d", curSize)
+			return scaleSet.instanceCache, nil
+		}
+		klog.V(2).Infof("Nodes: %d cached instances for vmss %q diverge from its capacity %d, forcing a refresh",
+			len(scaleSet.instanceCache), scaleSet.Name, curSize)
+		registerInstanceCacheInconsistency(scaleSet.Name)
+	}
+
 	klog.V(4).Infof("Nodes: starts to get VMSS VMs")
 	splay := rand.New(rand.NewSource(time.Now().UnixNano())).Intn(scaleSet.instancesRefreshJitter + 1)
 	lastRefresh := time.Now().Add(-time.Second * time.Duration(splay))
@@ -584,6 +706,8 @@ func (scaleSet *ScaleSet) buildScaleSetCache(lastRefresh time.Time) error {
 			// Log a warning and update the instance refresh time so that it would retry after cache expiration
 			klog.Warningf("GetScaleSetVms() is throttled with message %v, would return the cached instances", rerr)
 			scaleSet.lastInstanceRefresh = lastRefresh
+			scaleSet.throttledInstanceRefreshes++
+			scaleSet.nextAllowedRefresh = rerr.RetryAfter
 			return nil
 		}
 		return rerr.Error()
@@ -591,6 +715,8 @@ func (scaleSet *ScaleSet) buildScaleSetCache(lastRefresh time.Time) error {
 
 	scaleSet.instanceCache = buildInstanceCache(vms)
 	scaleSet.lastInstanceRefresh = lastRefresh
+	scaleSet.throttledInstanceRefreshes = 0
+	scaleSet.nextAllowedRefresh = time.Time{}
 
 	return nil
 }
@@ -602,6 +728,8 @@ func (scaleSet *ScaleSet) buildScaleSetCacheForFlex(lastRefresh time.Time) error
 			// Log a warning and update the instance refresh time so that it would retry after cache expiration
 			klog.Warningf("GetFlexibleScaleSetVms() is throttled with message %v, would return the cached instances", rerr)
 			scaleSet.lastInstanceRefresh = lastRefresh
+			scaleSet.throttledInstanceRefreshes++
+			scaleSet.nextAllowedRefresh = rerr.RetryAfter
 			return nil
 		}
 		return rerr.Error()
@@ -609,6 +737,8 @@ func (scaleSet *ScaleSet) buildScaleSetCacheForFlex(lastRefresh time.Time) error
 
 	scaleSet.instanceCache = buildInstanceCache(vms)
 	scaleSet.lastInstanceRefresh = lastRefresh
+	scaleSet.throttledInstanceRefreshes = 0
+	scaleSet.nextAllowedRefresh = time.Time{}
 
 	return nil
 }
@@ -671,10 +801,16 @@ func (scaleSet *ScaleSet) getInstanceByProviderID(providerID string) (cloudprovi
 }
 
 func (scaleSet *ScaleSet) setInstanceStatusByProviderID(providerID string, status cloudprovider.InstanceStatus) {
+	scaleSet.setInstanceStatusesByProviderIDs(map[string]cloudprovider.InstanceStatus{providerID: status})
+}
+
+// setInstanceStatusesByProviderIDs sets the status of the cached instances with the given provider IDs
+// under a single lock acquisition. Provider IDs which aren't cached are ignored.
+func (scaleSet *ScaleSet) setInstanceStatusesByProviderIDs(statuses map[string]cloudprovider.InstanceStatus) {
 	scaleSet.instanceMutex.Lock()
 	defer scaleSet.instanceMutex.Unlock()
 	for k, instance := range scaleSet.instanceCache {
-		if instance.Id == providerID {
+		if status, found := statuses[instance.Id]; found {
 			klog.V(5).Infof("Setting instance %s status to %v", instance.Id, status)
 			scaleSet.instanceCache[k].Status = &status
 		}
@@ -696,6 +832,15 @@ func instanceStatusFromProvisioningStateAndPowerState(resourceId string, provisi
 		status.State = cloudprovider.InstanceDeleting
 	case provisioningStateCreating:
 		status.State = cloudprovider.InstanceCreating
+	case provisioningStateUpdating:
+		// New instances can go through Updating (e.g. while their extensions are installed) before
+		// they start running. Existing instances are also Updating during model or extension changes,
+		// whether running, stopped or deallocated, and aren't being created.
+		if isNotStartedVmPowerState(powerState) {
+			status.State = cloudprovider.InstanceCreating
+		} else {
+			status.State = cloudprovider.InstanceRunning
+		}
 	case provisioningStateFailed:
 		// Provisioning can fail both during instance creation or after the instance is running.
 		// Per https://learn.microsoft.com/en-us/azure/virtual-machines/states-billing#provisioning-states,
@@ -720,10 +865,45 @@ func instanceStatusFromProvisioningStateAndPowerState(resourceId string, provisi
 	return status
 }
 
+// isInstanceCacheConsistent returns true if the cached instance count doesn't diverge from
+// the VMSS capacity by more than the configured threshold. Must be called with instanceMutex held.
+func (scaleSet *ScaleSet) isInstanceCacheConsistent(curSize int64) bool {
+	drift := int64(len(scaleSet.instanceCache)) - curSize
+	if drift < 0 {
+		drift = -drift
+	}
+	return drift <= int64(scaleSet.instancesConsistencyThreshold)
+}
+
+// instancesRefreshPeriodWithBackoff returns the instances refresh period, doubled for each consecutive
+// throttled refresh after the first, up to maxInstancesRefreshBackoffFactor times. Must be called with
+// instanceMutex held.
+func (scaleSet *ScaleSet) instancesRefreshPeriodWithBackoff() time.Duration {
+	factor := 1
+	for i := 1; i < scaleSet.throttledInstanceRefreshes && factor < maxInstancesRefreshBackoffFactor; i++ {
+		factor *= 2
+	}
+	return scaleSet.instancesRefreshPeriod * time.Duration(factor)
+}
+
+// nextInstanceRefresh returns when the instances cache expires. Must be called with instanceMutex held.
+func (scaleSet *ScaleSet) nextInstanceRefresh() time.Time {
+	return scaleSet.lastInstanceRefresh.Add(scaleSet.instancesRefreshPeriodWithBackoff() + scaleSet.instancesRefreshSpread)
+}
+
+// instancesRefreshSpread returns the duration added to the instances refresh period of a scale set, up to
+// instancesRefreshSpreadFraction of the period. It's derived from the scale set name so that it's stable
+// across refreshes, but differs between scale sets.
+func instancesRefreshSpread(name string, period time.Duration) time.Duration {
+	h := fnv.New32a()
+	h.Write([]byte(strings.ToLower(name)))
+	return time.Duration(float64(period) * instancesRefreshSpreadFraction * float64(h.Sum32()) / float64(^uint32(0)))
+}
+
 func (scaleSet *ScaleSet) invalidateInstanceCache() {
 	scaleSet.instanceMutex.Lock()
 	// Set the instanceCache as outdated.
-	scaleSet.lastInstanceRefresh = time.Now().Add(-1 * scaleSet.instancesRefreshPeriod)
+	scaleSet.lastInstanceRefresh = time.Now().Add(-1 * (scaleSet.instancesRefreshPeriodWithBackoff() + scaleSet.instancesRefreshSpread))
 	scaleSet.instanceMutex.Unlock()
 }
 
@@ -20,6 +20,7 @@ import (
 	"fmt"
 	"net/http"
 	"testing"
+	"time"
 
 	"github.com/Azure/azure-sdk-for-go/services/compute/mgmt/2022-08-01/compute"
 	"github.com/Azure/go-autorest/autorest/to"
@@ -27,9 +28,11 @@ import (
 	"github.com/stretchr/testify/assert"
 	apiv1 "k8s.io/api/core/v1"
 	"k8s.io/autoscaler/cluster-autoscaler/cloudprovider"
+	"k8s.io/autoscaler/cluster-autoscaler/config/dynamic"
 	"sigs.k8s.io/cloud-provider-azure/pkg/azureclients/vmclient/mockvmclient"
 	"sigs.k8s.io/cloud-provider-azure/pkg/azureclients/vmssclient/mockvmssclient"
 	"sigs.k8s.io/cloud-provider-azure/pkg/azureclients/vmssvmclient/mockvmssvmclient"
+	"sigs.k8s.io/cloud-provider-azure/pkg/retry"
 )
 
 func newTestScaleSet(manager *AzureManager, name string) *ScaleSet {
@@ -164,6 +167,63 @@ func TestTargetSize(t *testing.T) {
 	}
 }
 
+func TestNewScaleSetInstancesRefreshPeriod(t *testing.T) {
+	testCases := map[string]struct {
+		options        map[string]string
+		expectedPeriod time.Duration
+	}{
+		"default": {
+			expectedPeriod: defaultVmssInstancesRefreshPeriod,
+		},
+		"overridden by tag": {
+			options:        map[string]string{instancesRefreshPeriodOptionKey: "2m"},
+			expectedPeriod: 2 * time.Minute,
+		},
+		"invalid duration": {
+			options:        map[string]string{instancesRefreshPeriodOptionKey: "soon"},
+			expectedPeriod: defaultVmssInstancesRefreshPeriod,
+		},
+		"non-positive duration": {
+			options:        map[string]string{instancesRefreshPeriodOptionKey: "0s"},
+			expectedPeriod: defaultVmssInstancesRefreshPeriod,
+		},
+	}
+
+	for name, tc := range testCases {
+		t.Run(name, func(t *testing.T) {
+			manager := newTestAzureManager(t)
+			manager.azureCache.autoscalingOptions[azureRef{Name: "test-asg"}] = tc.options
+
+			scaleSet, err := NewScaleSet(&dynamic.NodeGroupSpec{Name: "test-asg", MinSize: 1, MaxSize: 5}, manager, 3)
+			assert.NoError(t, err)
+			assert.Equal(t, tc.expectedPeriod, scaleSet.instancesRefreshPeriod)
+		})
+	}
+}
+
+func TestNewScaleSetInstancesRefreshSpread(t *testing.T) {
+	manager := newTestAzureManager(t)
+	first, err := NewScaleSet(&dynamic.NodeGroupSpec{Name: "test-asg-1", MinSize: 1, MaxSize: 5}, manager, 3)
+	assert.NoError(t, err)
+	second, err := NewScaleSet(&dynamic.NodeGroupSpec{Name: "test-asg-2", MinSize: 1, MaxSize: 5}, manager, 3)
+	assert.NoError(t, err)
+
+	// Scale sets refreshed together expire at different times, within the spread of the refresh period.
+	lastRefresh := time.Now()
+	first.lastInstanceRefresh = lastRefresh
+	second.lastInstanceRefresh = lastRefresh
+	assert.NotEqual(t, first.nextInstanceRefresh(), second.nextInstanceRefresh())
+	maxSpread := time.Duration(float64(defaultVmssInstancesRefreshPeriod) * instancesRefreshSpreadFraction)
+	for _, scaleSet := range []*ScaleSet{first, second} {
+		assert.False(t, scaleSet.nextInstanceRefresh().Before(lastRefresh.Add(defaultVmssInstancesRefreshPeriod)))
+		assert.False(t, scaleSet.nextInstanceRefresh().After(lastRefresh.Add(defaultVmssInstancesRefreshPeriod+maxSpread)))
+	}
+
+	// The spread is kept when the cache is invalidated.
+	first.invalidateInstanceCache()
+	assert.False(t, first.nextInstanceRefresh().After(time.Now()))
+}
+
 func TestIncreaseSize(t *testing.T) {
 	ctrl := gomock.NewController(t)
 	defer ctrl.Finish()
@@ -217,6 +277,7 @@ func TestIncreaseSize(t *testing.T) {
 		// increase 3 nodes.
 		err = provider.NodeGroups()[0].IncreaseSize(2)
 		assert.NoError(t, err)
+		assert.NotZero(t, lastScaleActivity(t, "test-asg", scaleActivityUp))
 
 		// new target size should be 5.
 		targetSize, err = provider.NodeGroups()[0].TargetSize()
@@ -465,6 +526,7 @@ func TestDeleteNodes(t *testing.T) {
 		}
 		err = scaleSet.DeleteNodes(nodesToDelete)
 		assert.NoError(t, err)
+		assert.NotZero(t, lastScaleActivity(t, "test-asg", scaleActivityDown))
 
 		// create scale set with vmss capacity 1
 		expectedScaleSets = newTestVMSSList(1, vmssName, "eastus", orchMode)
@@ -762,6 +824,12 @@ func TestEnableVmssFlexFlag(t *testing.T) {
 func TestTemplateNodeInfo(t *testing.T) {
 	ctrl := gomock.NewController(t)
 	defer ctrl.Finish()
+	staticFunc := GetVMSSTypeStatically
+	dynamicFunc := GetVMSSTypeDynamically
+	defer func() {
+		GetVMSSTypeStatically = staticFunc
+		GetVMSSTypeDynamically = dynamicFunc
+	}()
 
 	expectedScaleSets := newTestVMSSList(3, "test-asg", "eastus", compute.Uniform)
 
@@ -850,3 +918,340 @@ func TestTemplateNodeInfo(t *testing.T) {
 		assert.NotEmpty(t, nodeInfo.Pods)
 	})
 }
+
+func TestScaleFromZeroDisabled(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	manager := newTestAzureManager(t)
+	expectedScaleSets := append(newTestVMSSList(3, "test-asg", "eastus", compute.Uniform),
+		newTestVMSSList(0, "test-asg-empty", "eastus", compute.Uniform)...)
+	for i := range expectedScaleSets {
+		expectedScaleSets[i].Tags = map[string]*string{scaleFromZeroDisabledTagName: to.StringPtr("true")}
+	}
+
+	mockVMSSClient := mockvmssclient.NewMockInterface(ctrl)
+	mockVMSSClient.EXPECT().List(gomock.Any(), manager.config.ResourceGroup).Return(expectedScaleSets, nil).AnyTimes()
+	mockVMSSClient.EXPECT().CreateOrUpdateAsync(gomock.Any(), manager.config.ResourceGroup, "test-asg", gomock.Any()).Return(nil, nil)
+	mockVMSSClient.EXPECT().WaitForCreateOrUpdateResult(gomock.Any(), gomock.Any(), manager.config.ResourceGroup).Return(&http.Response{StatusCode: http.StatusOK}, nil).AnyTimes()
+	mockVMSSClient.EXPECT().DeleteInstancesAsync(gomock.Any(), manager.config.ResourceGroup, gomock.Any(), gomock.Any(), false).Return(nil, nil)
+	mockVMSSClient.EXPECT().WaitForDeleteInstancesResult(gomock.Any(), gomock.Any(), manager.config.ResourceGroup).Return(&http.Response{StatusCode: http.StatusOK}, nil).AnyTimes()
+	manager.azClient.virtualMachineScaleSetsClient = mockVMSSClient
+	mockVMSSVMClient := mockvmssvmclient.NewMockInterface(ctrl)
+	mockVMSSVMClient.EXPECT().List(gomock.Any(), manager.config.ResourceGroup, "test-asg", gomock.Any()).Return(newTestVMSSVMList(3), nil).AnyTimes()
+	mockVMSSVMClient.EXPECT().List(gomock.Any(), manager.config.ResourceGroup, "test-asg-empty", gomock.Any()).Return([]compute.VirtualMachineScaleSetVM{}, nil).AnyTimes()
+	manager.azClient.virtualMachineScaleSetVMsClient = mockVMSSVMClient
+
+	emptyScaleSet := newTestScaleSet(manager, "test-asg-empty")
+	emptyScaleSet.minSize = 0
+	scaleSet := newTestScaleSet(manager, "test-asg")
+	assert.True(t, manager.RegisterNodeGroup(emptyScaleSet))
+	assert.True(t, manager.RegisterNodeGroup(scaleSet))
+	manager.explicitlyConfigured["test-asg"] = true
+	manager.explicitlyConfigured["test-asg-empty"] = true
+	assert.NoError(t, manager.forceRefresh())
+
+	// Scale from zero is blocked: no template and no size increase.
+	nodeInfo, err := emptyScaleSet.TemplateNodeInfo()
+	assert.Nil(t, nodeInfo)
+	assert.Equal(t, cloudprovider.ErrNotImplemented, err)
+	err = emptyScaleSet.IncreaseSize(1)
+	assert.Equal(t, fmt.Errorf("scale from zero is disabled for the scale set test-asg-empty"), err)
+
+	// Scale from existing nodes and scale down still work.
+	assert.NoError(t, scaleSet.IncreaseSize(1))
+	assert.NoError(t, scaleSet.DeleteNodes([]*apiv1.Node{newApiNode(compute.Uniform, 0)}))
+}
+
+func TestScaleDownDeferredDuringAutomaticOSUpgrade(t *testing.T) {
+	rollingUpgradeStatus := func(code compute.RollingUpgradeStatusCode) compute.RollingUpgradeStatusInfo {
+		return compute.RollingUpgradeStatusInfo{
+			RollingUpgradeStatusInfoProperties: &compute.RollingUpgradeStatusInfoProperties{
+				RunningStatus: &compute.RollingUpgradeRunningStatus{Code: code},
+			},
+		}
+	}
+
+	testCases := []struct {
+		name                string
+		autoUpgradeEnabled  bool
+		rollingUpgrades     map[string]compute.RollingUpgradeStatusInfo
+		expectedDeferred    bool
+		expectedUpgradeCall bool
+	}{
+		{
+			name:                "rolling upgrade in progress defers scale down",
+			autoUpgradeEnabled:  true,
+			rollingUpgrades:     map[string]compute.RollingUpgradeStatusInfo{"vmss-upgrading": rollingUpgradeStatus(compute.RollingUpgradeStatusCodeRollingForward)},
+			expectedDeferred:    true,
+			expectedUpgradeCall: true,
+		},
+		{
+			name:                "scale set updating for another reason doesn't defer scale down",
+			autoUpgradeEnabled:  true,
+			rollingUpgrades:     map[string]compute.RollingUpgradeStatusInfo{"vmss-upgrading": rollingUpgradeStatus(compute.RollingUpgradeStatusCodeCompleted)},
+			expectedUpgradeCall: true,
+		},
+		{
+			name:                "scale set never upgraded doesn't defer scale down",
+			autoUpgradeEnabled:  true,
+			expectedUpgradeCall: true,
+		},
+		{
+			name:            "automatic OS upgrades disabled don't defer scale down",
+			rollingUpgrades: map[string]compute.RollingUpgradeStatusInfo{"vmss-upgrading": rollingUpgradeStatus(compute.RollingUpgradeStatusCodeRollingForward)},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			manager := newTestAzureManager(t)
+			vmssName := "vmss-upgrading"
+			expectedScaleSets := newTestVMSSList(3, vmssName, "eastus", compute.Uniform)
+			expectedScaleSets[0].ProvisioningState = to.StringPtr(provisioningStateUpdating)
+			expectedScaleSets[0].UpgradePolicy = &compute.UpgradePolicy{
+				AutomaticOSUpgradePolicy: &compute.AutomaticOSUpgradePolicy{
+					EnableAutomaticOSUpgrade: to.BoolPtr(tc.autoUpgradeEnabled),
+				},
+			}
+
+			mockVMSSClient := mockvmssclient.NewMockInterface(ctrl)
+			mockVMSSClient.EXPECT().List(gomock.Any(), manager.config.ResourceGroup).Return(expectedScaleSets, nil).AnyTimes()
+			manager.azClient.virtualMachineScaleSetsClient = mockVMSSClient
+			mockVMSSVMClient := mockvmssvmclient.NewMockInterface(ctrl)
+			mockVMSSVMClient.EXPECT().List(gomock.Any(), manager.config.ResourceGroup, vmssName, gomock.Any()).Return(newTestVMSSVMList(3), nil).AnyTimes()
+			manager.azClient.virtualMachineScaleSetVMsClient = mockVMSSVMClient
+			rollingUpgradesClient := &RollingUpgradesClientMock{FakeStore: tc.rollingUpgrades}
+			manager.azClient.rollingUpgradesClient = rollingUpgradesClient
+			manager.explicitlyConfigured[vmssName] = true
+			scaleSet := newTestScaleSet(manager, vmssName)
+			scaleSet.sizeRefreshPeriod = time.Minute
+			assert.True(t, manager.RegisterNodeGroup(scaleSet))
+			assert.NoError(t, manager.forceRefresh())
+
+			deferred, reason := scaleSet.ScaleDownDeferred()
+			assert.Equal(t, tc.expectedDeferred, deferred)
+			if tc.expectedDeferred {
+				assert.Equal(t, "automatic OS upgrade in progress", reason)
+			}
+
+			// The rolling upgrade status is cached for the size refresh period.
+			deferred, _ = scaleSet.ScaleDownDeferred()
+			assert.Equal(t, tc.expectedDeferred, deferred)
+			expectedCalls := 0
+			if tc.expectedUpgradeCall {
+				expectedCalls = 1
+			}
+			assert.Equal(t, expectedCalls, rollingUpgradesClient.calls)
+		})
+	}
+}
+
+func TestScaleSetNodesInstanceCacheConsistency(t *testing.T) {
+	testCases := []struct {
+		name              string
+		threshold         int
+		throttled         bool
+		expectedListCalls int
+	}{
+		{
+			name:              "divergence above threshold forces a refresh",
+			threshold:         1,
+			expectedListCalls: 2,
+		},
+		{
+			name:              "divergence within threshold uses the cache",
+			threshold:         2,
+			expectedListCalls: 1,
+		},
+		{
+			name:              "throttled refresh isn't retried before the cache TTL",
+			threshold:         0,
+			throttled:         true,
+			expectedListCalls: 1,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			provider := newTestProvider(t)
+			manager := provider.azureManager
+			// The VMSS capacity is 3 while only a single instance is listed.
+			expectedScaleSets := newTestVMSSList(3, "test-asg", "eastus", compute.Uniform)
+			mockVMSSClient := mockvmssclient.NewMockInterface(ctrl)
+			mockVMSSClient.EXPECT().List(gomock.Any(), manager.config.ResourceGroup).Return(expectedScaleSets, nil).AnyTimes()
+			manager.azClient.virtualMachineScaleSetsClient = mockVMSSClient
+			mockVMSSVMClient := mockvmssvmclient.NewMockInterface(ctrl)
+			if tc.throttled {
+				mockVMSSVMClient.EXPECT().List(gomock.Any(), manager.config.ResourceGroup, "test-asg", gomock.Any()).Return(nil, &rerrTooManyReqs).Times(tc.expectedListCalls)
+			} else {
+				mockVMSSVMClient.EXPECT().List(gomock.Any(), manager.config.ResourceGroup, "test-asg", gomock.Any()).Return(newTestVMSSVMList(1), nil).Times(tc.expectedListCalls)
+			}
+			manager.azClient.virtualMachineScaleSetVMsClient = mockVMSSVMClient
+
+			scaleSet := newTestScaleSet(manager, "test-asg")
+			scaleSet.instancesRefreshPeriod = defaultVmssInstancesRefreshPeriod
+			scaleSet.instancesConsistencyThreshold = tc.threshold
+			manager.explicitlyConfigured["test-asg"] = true
+			assert.True(t, manager.RegisterNodeGroup(scaleSet))
+			// Refreshing the manager populates the instances cache.
+			assert.NoError(t, manager.forceRefresh())
+
+			_, err := scaleSet.Nodes()
+			assert.NoError(t, err)
+		})
+	}
+}
+
+func TestScaleSetNodesThrottledRefreshBackoff(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	provider := newTestProvider(t)
+	manager := provider.azureManager
+	expectedScaleSets := newTestVMSSList(3, "test-asg", "eastus", compute.Uniform)
+	mockVMSSClient := mockvmssclient.NewMockInterface(ctrl)
+	mockVMSSClient.EXPECT().List(gomock.Any(), manager.config.ResourceGroup).Return(expectedScaleSets, nil).AnyTimes()
+	manager.azClient.virtualMachineScaleSetsClient = mockVMSSClient
+	mockVMSSVMClient := mockvmssvmclient.NewMockInterface(ctrl)
+	mockVMSSVMClient.EXPECT().List(gomock.Any(), manager.config.ResourceGroup, "test-asg", gomock.Any()).Return(nil, &rerrTooManyReqs).Times(3)
+	mockVMSSVMClient.EXPECT().List(gomock.Any(), manager.config.ResourceGroup, "test-asg", gomock.Any()).Return(newTestVMSSVMList(3), nil).Times(1)
+	manager.azClient.virtualMachineScaleSetVMsClient = mockVMSSVMClient
+
+	scaleSet := newTestScaleSet(manager, "test-asg")
+	scaleSet.instancesRefreshPeriod = defaultVmssInstancesRefreshPeriod
+	manager.explicitlyConfigured["test-asg"] = true
+	assert.True(t, manager.RegisterNodeGroup(scaleSet))
+	// Refreshing the manager populates the instances cache, with a first throttled refresh.
+	assert.NoError(t, manager.forceRefresh())
+	assert.Equal(t, 1, scaleSet.throttledInstanceRefreshes)
+	assert.Equal(t, defaultVmssInstancesRefreshPeriod, scaleSet.instancesRefreshPeriodWithBackoff())
+
+	// Expire the cache twice more, lengthening the refresh period after each throttled refresh.
+	for _, expectedPeriod := range []time.Duration{2 * defaultVmssInstancesRefreshPeriod, 4 * defaultVmssInstancesRefreshPeriod} {
+		scaleSet.lastInstanceRefresh = time.Now().Add(-scaleSet.instancesRefreshPeriodWithBackoff() - time.Second)
+		_, err := scaleSet.Nodes()
+		assert.NoError(t, err)
+		assert.Equal(t, expectedPeriod, scaleSet.instancesRefreshPeriodWithBackoff())
+	}
+	assert.Equal(t, 3, scaleSet.throttledInstanceRefreshes)
+
+	// The next refresh is deferred past the regular refresh period.
+	scaleSet.lastInstanceRefresh = time.Now().Add(-2 * defaultVmssInstancesRefreshPeriod)
+	_, err := scaleSet.Nodes()
+	assert.NoError(t, err)
+
+	// A successful refresh resets the backoff.
+	scaleSet.lastInstanceRefresh = time.Now().Add(-4*defaultVmssInstancesRefreshPeriod - time.Second)
+	instances, err := scaleSet.Nodes()
+	assert.NoError(t, err)
+	assert.Len(t, instances, 3)
+	assert.Equal(t, 0, scaleSet.throttledInstanceRefreshes)
+	assert.Equal(t, defaultVmssInstancesRefreshPeriod, scaleSet.instancesRefreshPeriodWithBackoff())
+}
+
+func TestSetInstanceStatusesByProviderIDs(t *testing.T) {
+	newInstanceCache := func() []cloudprovider.Instance {
+		return []cloudprovider.Instance{
+			{Id: "azure:///vm-0", Status: &cloudprovider.InstanceStatus{State: cloudprovider.InstanceRunning}},
+			{Id: "azure:///vm-1", Status: &cloudprovider.InstanceStatus{State: cloudprovider.InstanceRunning}},
+			{Id: "azure:///vm-2", Status: &cloudprovider.InstanceStatus{State: cloudprovider.InstanceRunning}},
+		}
+	}
+	statuses := map[string]cloudprovider.InstanceStatus{
+		"azure:///vm-0":      {State: cloudprovider.InstanceDeleting},
+		"azure:///vm-2":      {State: cloudprovider.InstanceCreating},
+		"azure:///vm-absent": {State: cloudprovider.InstanceDeleting},
+	}
+
+	single := newTestScaleSet(nil, "test-asg")
+	single.instanceCache = newInstanceCache()
+	for providerID, status := range statuses {
+		single.setInstanceStatusByProviderID(providerID, status)
+	}
+
+	bulk := newTestScaleSet(nil, "test-asg")
+	bulk.instanceCache = newInstanceCache()
+	bulk.setInstanceStatusesByProviderIDs(statuses)
+
+	assert.Equal(t, single.instanceCache, bulk.instanceCache)
+	assert.Len(t, bulk.instanceCache, 3)
+	assert.Equal(t, cloudprovider.InstanceDeleting, bulk.instanceCache[0].Status.State)
+	assert.Equal(t, cloudprovider.InstanceRunning, bulk.instanceCache[1].Status.State)
+	assert.Equal(t, cloudprovider.InstanceCreating, bulk.instanceCache[2].Status.State)
+	assert.False(t, bulk.lastInstanceRefresh.IsZero())
+}
+
+func TestBuildInstanceCacheProvisioningStates(t *testing.T) {
+	vms := newTestVMSSVMList(7)
+	vms[0].ProvisioningState = to.StringPtr(provisioningStateCreating)
+	vms[1].ProvisioningState = to.StringPtr(provisioningStateUpdating)
+	vms[1].InstanceView = &compute.VirtualMachineScaleSetVMInstanceView{
+		Statuses: &[]compute.InstanceViewStatus{{Code: to.StringPtr("ProvisioningState/updating")}},
+	}
+	vms[2].ProvisioningState = to.StringPtr(provisioningStateUpdating)
+	vms[2].InstanceView = &compute.VirtualMachineScaleSetVMInstanceView{
+		Statuses: &[]compute.InstanceViewStatus{{Code: to.StringPtr(vmPowerStateRunning)}},
+	}
+	vms[3].ProvisioningState = to.StringPtr(provisioningStateSucceeded)
+	for i, powerState := range map[int]string{4: vmPowerStateStarting, 5: vmPowerStateDeallocated, 6: vmPowerStateStopped} {
+		vms[i].ProvisioningState = to.StringPtr(provisioningStateUpdating)
+		vms[i].InstanceView = &compute.VirtualMachineScaleSetVMInstanceView{
+			Statuses: &[]compute.InstanceViewStatus{{Code: to.StringPtr(powerState)}},
+		}
+	}
+
+	instances := buildInstanceCache(vms)
+	assert.Len(t, instances, 7)
+	assert.Equal(t, cloudprovider.InstanceCreating, instances[0].Status.State)
+	assert.Equal(t, cloudprovider.InstanceCreating, instances[1].Status.State)
+	assert.Equal(t, cloudprovider.InstanceRunning, instances[2].Status.State)
+	assert.Equal(t, cloudprovider.InstanceRunning, instances[3].Status.State)
+	// Updating instances which are starting haven't run yet, stopped or deallocated ones aren't created.
+	assert.Equal(t, cloudprovider.InstanceCreating, instances[4].Status.State)
+	assert.Equal(t, cloudprovider.InstanceRunning, instances[5].Status.State)
+	assert.Equal(t, cloudprovider.InstanceRunning, instances[6].Status.State)
+}
+
+func TestScaleSetNodesThrottledRefreshRetryAfter(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	provider := newTestProvider(t)
+	manager := provider.azureManager
+	expectedScaleSets := newTestVMSSList(3, "test-asg", "eastus", compute.Uniform)
+	mockVMSSClient := mockvmssclient.NewMockInterface(ctrl)
+	mockVMSSClient.EXPECT().List(gomock.Any(), manager.config.ResourceGroup).Return(expectedScaleSets, nil).AnyTimes()
+	manager.azClient.virtualMachineScaleSetsClient = mockVMSSClient
+	retryAfter := time.Now().Add(time.Hour)
+	rerrRetryAfter := retry.Error{HTTPStatusCode: http.StatusTooManyRequests, RetryAfter: retryAfter}
+	mockVMSSVMClient := mockvmssvmclient.NewMockInterface(ctrl)
+	mockVMSSVMClient.EXPECT().List(gomock.Any(), manager.config.ResourceGroup, "test-asg", gomock.Any()).Return(nil, &rerrRetryAfter).Times(1)
+	mockVMSSVMClient.EXPECT().List(gomock.Any(), manager.config.ResourceGroup, "test-asg", gomock.Any()).Return(newTestVMSSVMList(3), nil).Times(1)
+	manager.azClient.virtualMachineScaleSetVMsClient = mockVMSSVMClient
+
+	scaleSet := newTestScaleSet(manager, "test-asg")
+	manager.explicitlyConfigured["test-asg"] = true
+	assert.True(t, manager.RegisterNodeGroup(scaleSet))
+	assert.NoError(t, manager.forceRefresh())
+	assert.Equal(t, retryAfter, scaleSet.nextAllowedRefresh)
+
+	// The expired cache isn't refreshed before the Retry-After hint.
+	scaleSet.invalidateInstanceCache()
+	instances, err := scaleSet.Nodes()
+	assert.NoError(t, err)
+	assert.Empty(t, instances)
+
+	// Once the hint elapses, the cache is refreshed and the hint cleared.
+	scaleSet.nextAllowedRefresh = time.Now().Add(-time.Second)
+	instances, err = scaleSet.Nodes()
+	assert.NoError(t, err)
+	assert.Len(t, instances, 3)
+	assert.True(t, scaleSet.nextAllowedRefresh.IsZero())
+}
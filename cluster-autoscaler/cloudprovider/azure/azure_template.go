@@ -17,7 +17,9 @@ limitations under the License.
 package azure
 
 import (
+	"context"
 	"fmt"
+	"math"
 	"math/rand"
 	"regexp"
 	"strconv"
@@ -25,9 +27,11 @@ import (
 	"time"
 
 	"github.com/Azure/azure-sdk-for-go/services/compute/mgmt/2022-08-01/compute"
+	"github.com/Azure/skewer"
 	apiv1 "k8s.io/api/core/v1"
 	"k8s.io/apimachinery/pkg/api/resource"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/util/validation"
 	"k8s.io/autoscaler/cluster-autoscaler/cloudprovider"
 	"k8s.io/autoscaler/cluster-autoscaler/utils/gpu"
 	cloudvolume "k8s.io/cloud-provider/volume"
@@ -36,8 +40,52 @@ import (
 
 const (
 	azureDiskTopologyKey string = "topology.disk.csi.azure.com/zone"
+
+	// AKS label and taint applied to nodes of Spot scale sets.
+	scaleSetPriorityLabelKey = "kubernetes.azure.com/scalesetpriority"
+	scaleSetPrioritySpot     = "spot"
+
+	// AKS label giving the security type of nodes of confidential VM and trusted launch scale sets.
+	securityTypeLabelKey = "kubernetes.azure.com/security-type"
+
+	// Sources of the ephemeral storage of nodes, selected by nodeEphemeralStorageSourceTagName.
+	ephemeralStorageSourceOS   = "os"
+	ephemeralStorageSourceTemp = "temp"
+	ephemeralStorageSourceData = "data"
+
+	// Default max pods per node, matching the AKS defaults for each OS.
+	defaultLinuxMaxPods   = 110
+	defaultWindowsMaxPods = 30
+
+	// Network plugins given by nodeNetworkPluginTagName.
+	networkPluginKubenet      = "kubenet"
+	networkPluginAzure        = "azure"
+	networkPluginAzureOverlay = "azure-overlay"
+
+	// podsCapacitySourceAnnotation records on template nodes what determined their pods capacity.
+	podsCapacitySourceAnnotation = "cluster-autoscaler.kubernetes.io/pods-capacity-source"
+	// Sources of the pods capacity of template nodes.
+	podsCapacitySourceTag           = "tag"
+	podsCapacitySourceOSDefault     = "os-default"
+	podsCapacitySourceNetworkPlugin = "network-plugin-default"
+	podsCapacitySourceFallback      = "fallback-110"
 )
 
+// networkPluginMaxPods are the AKS default max pods per node for each network plugin.
+var networkPluginMaxPods = map[string]int64{
+	networkPluginKubenet:      110,
+	networkPluginAzure:        30,
+	networkPluginAzureOverlay: 250,
+}
+
+// defaultWindowsReservedResources are the resources reserved for the kubelet, container runtime and
+// OS services on Windows nodes, which are considerably larger than on Linux. They can be overridden per
+// scale set with the nodeWindowsReservedTagName tags.
+var defaultWindowsReservedResources = apiv1.ResourceList{
+	apiv1.ResourceCPU:    resource.MustParse("100m"),
+	apiv1.ResourceMemory: resource.MustParse("2Gi"),
+}
+
 func buildInstanceOS(template compute.VirtualMachineScaleSet) string {
 	instanceOS := cloudprovider.DefaultOS
 	if template.VirtualMachineProfile != nil && template.VirtualMachineProfile.OsProfile != nil && template.VirtualMachineProfile.OsProfile.WindowsConfiguration != nil {
@@ -47,19 +95,24 @@ func buildInstanceOS(template compute.VirtualMachineScaleSet) string {
 	return instanceOS
 }
 
+// buildGenericLabels returns the well-known labels of a node from the given scale set.
+// Zone labels use the full <region>-<zone> Azure zone ID, as set by the Azure cloud provider on real nodes:
+// a scale set spanning a single zone gets that zone, a scale set spanning multiple zones gets
+// all of them joined with the multi-zone delimiter, and a non-zonal scale set gets "0".
 func buildGenericLabels(template compute.VirtualMachineScaleSet, nodeName string) map[string]string {
 	result := make(map[string]string)
 
 	result[apiv1.LabelArchStable] = cloudprovider.DefaultArch
 	result[apiv1.LabelOSStable] = buildInstanceOS(template)
 
+	location := strings.ToLower(strings.TrimSpace(*template.Location))
 	result[apiv1.LabelInstanceTypeStable] = *template.Sku.Name
-	result[apiv1.LabelTopologyRegion] = strings.ToLower(*template.Location)
+	result[apiv1.LabelTopologyRegion] = location
 
 	if template.Zones != nil && len(*template.Zones) > 0 {
 		failureDomains := make([]string, len(*template.Zones))
 		for k, v := range *template.Zones {
-			failureDomains[k] = strings.ToLower(*template.Location) + "-" + v
+			failureDomains[k] = location + "-" + v
 		}
 
 		result[apiv1.LabelTopologyZone] = strings.Join(failureDomains[:], cloudvolume.LabelMultiZoneDelimiter)
@@ -74,6 +127,13 @@ func buildGenericLabels(template compute.VirtualMachineScaleSet, nodeName string
 }
 
 func buildNodeFromTemplate(scaleSetName string, template compute.VirtualMachineScaleSet, manager *AzureManager) (*apiv1.Node, error) {
+	if template.Location == nil || strings.TrimSpace(*template.Location) == "" {
+		return nil, fmt.Errorf("vmss %q has no location", scaleSetName)
+	}
+	if template.Sku == nil || template.Sku.Name == nil {
+		return nil, fmt.Errorf("vmss %q has no SKU name", scaleSetName)
+	}
+
 	node := apiv1.Node{}
 	nodeName := fmt.Sprintf("%s-asg-%d", scaleSetName, rand.Int63())
 
@@ -88,6 +148,9 @@ func buildNodeFromTemplate(scaleSetName string, template compute.VirtualMachineS
 	}
 
 	var vcpu, gpuCount, memoryMb int64
+	// skuSource is where the SKU information comes from: the SKU API (dynamic), the static list,
+	// or the SKU API fallback for SKUs missing from the static list
+	var skuSource string
 
 	// Fetching SKU information from SKU API if enableDynamicInstanceList is true.
 	var dynamicErr error
@@ -96,9 +159,15 @@ func buildNodeFromTemplate(scaleSetName string, template compute.VirtualMachineS
 		klog.V(1).Infof("Fetching instance information for SKU: %s from SKU API", *template.Sku.Name)
 		vmssTypeDynamic, dynamicErr = GetVMSSTypeDynamically(template, manager.azureCache)
 		if dynamicErr == nil {
+			skuSource = "dynamic"
 			vcpu = vmssTypeDynamic.VCPU
 			gpuCount = vmssTypeDynamic.GPU
 			memoryMb = vmssTypeDynamic.MemoryMb
+			// The dynamic SKU information is authoritative, but report any disagreement
+			// with the static list to help keep it accurate.
+			if vmssTypeStatic, staticErr := GetVMSSTypeStatically(template); staticErr == nil {
+				reportInstanceTypeDeltas(manager.azureCache, *template.Sku.Name, compareInstanceTypes(vmssTypeDynamic, *vmssTypeStatic))
+			}
 		} else {
 			klog.Errorf("Dynamically fetching of instance information from SKU api failed with error: %v", dynamicErr)
 		}
@@ -108,37 +177,77 @@ func buildNodeFromTemplate(scaleSetName string, template compute.VirtualMachineS
 		// fall-back on static list of vmss if dynamic workflow fails.
 		vmssTypeStatic, staticErr := GetVMSSTypeStatically(template)
 		if staticErr == nil {
+			skuSource = "static"
 			vcpu = vmssTypeStatic.VCPU
 			gpuCount = vmssTypeStatic.GPU
 			memoryMb = vmssTypeStatic.MemoryMb
+		} else if vmssTypeDynamic, fallbackErr := getVMSSTypeFromFallback(template, manager); fallbackErr == nil {
+			skuSource = "fallback"
+			vcpu = vmssTypeDynamic.VCPU
+			gpuCount = vmssTypeDynamic.GPU
+			memoryMb = vmssTypeDynamic.MemoryMb
 		} else {
 			// return error if neither of the workflows results with vmss data.
 			klog.V(1).Infof("Instance type %q not supported, err: %v", *template.Sku.Name, staticErr)
 			return nil, staticErr
 		}
 	}
+	if memoryMb < 0 || memoryMb > math.MaxInt64/(1024*1024) {
+		return nil, fmt.Errorf("vmss %q has invalid memory for SKU %q: %d MiB", scaleSetName, *template.Sku.Name, memoryMb)
+	}
+	klog.V(4).InfoS("Resolved template node SKU", "nodeGroupName", scaleSetName, "sku", *template.Sku.Name,
+		"source", skuSource, "vcpu", vcpu, "memoryMb", memoryMb, "gpu", gpuCount)
 
-	node.Status.Capacity[apiv1.ResourcePods] = *resource.NewQuantity(110, resource.DecimalSI)
+	instanceOS := buildInstanceOS(template)
+	maxPods, podsCapacitySource := TemplateNodeMaxPods(template)
+	node.Status.Capacity[apiv1.ResourcePods] = *resource.NewQuantity(maxPods, resource.DecimalSI)
 	node.Status.Capacity[apiv1.ResourceCPU] = *resource.NewQuantity(vcpu, resource.DecimalSI)
-	// isNPSeries returns if a SKU is an NP-series SKU
+	gpuVendor := getGpuVendorFromSku(*template.Sku.Name)
+	switch {
+	case gpuVendor == gpuVendorAMD:
+		node.Status.Capacity[resourceAMDGPU] = *resource.NewQuantity(gpuCount, resource.DecimalSI)
 	// SKU API reports GPUs for NP-series but it's actually FPGAs
-	if !isNPSeries(*template.Sku.Name) {
+	case isNPSeries(*template.Sku.Name):
+		node.Status.Capacity[fpgaResourceName(manager.config)] = *resource.NewQuantity(gpuCount, resource.DecimalSI)
+	default:
 		node.Status.Capacity[gpu.ResourceNvidiaGPU] = *resource.NewQuantity(gpuCount, resource.DecimalSI)
 	}
+	if gpuVendor != gpuVendorNone {
+		node.Labels[GPULabel] = gpuVendor
+	}
+	if gpuVendor == gpuVendorNvidia {
+		if product := getNvidiaGpuProductFromSku(*template.Sku.Name); product != "" {
+			node.Labels[nvidiaGpuProductLabel] = product
+		}
+	}
 
 	node.Status.Capacity[apiv1.ResourceMemory] = *resource.NewQuantity(memoryMb*1024*1024, resource.DecimalSI)
+	if ephemeralStorage, found := buildEphemeralStorage(template, manager); found {
+		node.Status.Capacity[apiv1.ResourceEphemeralStorage] = *resource.NewQuantity(ephemeralStorage, resource.DecimalSI)
+	}
 
 	resourcesFromTags := extractAllocatableResourcesFromScaleSet(template.Tags)
 	for resourceName, val := range resourcesFromTags {
 		node.Status.Capacity[apiv1.ResourceName(resourceName)] = *val
 	}
-
-	// TODO: set real allocatable.
-	node.Status.Allocatable = node.Status.Capacity
-
-	// NodeLabels
+	node.Annotations = extractAnnotationsFromScaleSet(template.Tags)
+	node.Annotations[podsCapacitySourceAnnotation] = podsCapacitySource
+	registerTemplatePodsCapacity(scaleSetName, podsCapacitySource, maxPods)
+
+	// TODO: set real allocatable for Linux.
+	node.Status.Allocatable = buildAllocatable(node.Status.Capacity, instanceOS, template.Tags)
+	klog.V(4).InfoS("Computed template node allocatable", "nodeGroupName", scaleSetName, "sku", *template.Sku.Name,
+		"cpu", node.Status.Allocatable.Cpu().String(), "memory", node.Status.Allocatable.Memory().String(),
+		"pods", node.Status.Allocatable.Pods().String())
+
+	// NodeLabels, except for the tags encoding the template node, which are decoded below, and the
+	// autoscaler control tags
+	customLabelPrefix := manager.config.NodeLabelTagPrefix
 	if template.Tags != nil {
 		for k, v := range template.Tags {
+			if strings.HasPrefix(k, nodeTemplateTagPrefix) || (customLabelPrefix != "" && strings.HasPrefix(k, customLabelPrefix)) || k == scaleFromZeroDisabledTagName {
+				continue
+			}
 			if v != nil {
 				node.Labels[k] = *v
 			} else {
@@ -151,23 +260,231 @@ func buildNodeFromTemplate(scaleSetName string, template compute.VirtualMachineS
 	// GenericLabels
 	node.Labels = cloudprovider.JoinStringMaps(node.Labels, buildGenericLabels(template, nodeName))
 	// Labels from the Scale Set's Tags
-	node.Labels = cloudprovider.JoinStringMaps(node.Labels, extractLabelsFromScaleSet(template.Tags))
+	node.Labels = cloudprovider.JoinStringMaps(node.Labels, extractLabelsFromScaleSet(template.Tags, customLabelPrefix))
 
 	// Taints from the Scale Set's Tags
 	node.Spec.Taints = extractTaintsFromScaleSet(template.Tags)
 
-	node.Status.Conditions = cloudprovider.BuildReadyConditions()
+	// Spot label and taint, unless explicitly set on the Scale Set's Tags
+	if isSpotScaleSet(template) {
+		if _, found := node.Labels[scaleSetPriorityLabelKey]; !found {
+			node.Labels[scaleSetPriorityLabelKey] = scaleSetPrioritySpot
+		}
+		if !hasTaint(node.Spec.Taints, scaleSetPriorityLabelKey) {
+			node.Spec.Taints = append(node.Spec.Taints, apiv1.Taint{
+				Key:    scaleSetPriorityLabelKey,
+				Value:  scaleSetPrioritySpot,
+				Effect: apiv1.TaintEffectNoSchedule,
+			})
+		}
+	}
+
+	// Security type label, unless explicitly set on the Scale Set's Tags
+	if securityType := buildSecurityType(template); securityType != "" {
+		if _, found := node.Labels[securityTypeLabelKey]; !found {
+			node.Labels[securityTypeLabelKey] = securityType
+		}
+	}
+
+	node.Status.Conditions = buildConditions(template.Tags)
 	return &node, nil
 }
 
-func extractLabelsFromScaleSet(tags map[string]*string) map[string]string {
+// getVMSSTypeFromFallback looks up SKUs missing from the static list with the SKU API, when the dynamic
+// instance list is disabled but enableDynamicInstanceListFallback is set, so new SKUs can scale from zero.
+func getVMSSTypeFromFallback(template compute.VirtualMachineScaleSet, manager *AzureManager) (InstanceType, error) {
+	if manager.config.EnableDynamicInstanceList || !manager.config.EnableDynamicInstanceListFallback {
+		return InstanceType{}, fmt.Errorf("dynamic instance list fallback disabled")
+	}
+	klog.V(1).Infof("SKU %s missing from the static SKU list, fetching its information from SKU API", *template.Sku.Name)
+	vmssType, err := GetVMSSTypeDynamically(template, manager.azureCache)
+	if err != nil {
+		klog.Errorf("Dynamically fetching of instance information from SKU api failed with error: %v", err)
+	}
+	return vmssType, err
+}
+
+// TemplateNodeMaxPods returns the pods capacity of the template nodes of a scale set, and its source:
+// the pods resource tag, the default of the network plugin tag, the Windows default, or the kubelet
+// default of 110 otherwise.
+func TemplateNodeMaxPods(template compute.VirtualMachineScaleSet) (int64, string) {
+	if raw, found := template.Tags[nodeResourcesTagName+string(apiv1.ResourcePods)]; found && raw != nil {
+		if pods, err := resource.ParseQuantity(*raw); err == nil {
+			return pods.Value(), podsCapacitySourceTag
+		}
+	}
+	if raw, found := template.Tags[nodeNetworkPluginTagName]; found && raw != nil {
+		if pods, found := networkPluginMaxPods[strings.ToLower(*raw)]; found {
+			return pods, podsCapacitySourceNetworkPlugin
+		}
+		klog.Warningf("Ignoring tag %s with unknown network plugin %q", nodeNetworkPluginTagName, *raw)
+	}
+	if buildInstanceOS(template) == "windows" {
+		return defaultWindowsMaxPods, podsCapacitySourceOSDefault
+	}
+	return defaultLinuxMaxPods, podsCapacitySourceFallback
+}
+
+// buildAllocatable returns the allocatable resources of a node with the given capacity, minus the kubelet
+// reservations set by tags, or the Windows default reservations. Allocatable equals capacity otherwise.
+func buildAllocatable(capacity apiv1.ResourceList, instanceOS string, tags map[string]*string) apiv1.ResourceList {
+	allocatable := capacity.DeepCopy()
+
+	reserved := apiv1.ResourceList{}
+	kubeReserved := extractReservedResourcesFromScaleSet(tags, nodeKubeReservedTagName)
+	systemReserved := extractReservedResourcesFromScaleSet(tags, nodeSystemReservedTagName)
+	if len(kubeReserved) > 0 || len(systemReserved) > 0 {
+		// The kubelet reservations replace the default ones
+		for _, reservation := range []map[string]*resource.Quantity{kubeReserved, systemReserved} {
+			for resourceName, val := range reservation {
+				quantity := reserved[apiv1.ResourceName(resourceName)]
+				quantity.Add(*val)
+				reserved[apiv1.ResourceName(resourceName)] = quantity
+			}
+		}
+	} else if instanceOS == "windows" {
+		reserved = defaultWindowsReservedResources.DeepCopy()
+		for resourceName, val := range extractReservedResourcesFromScaleSet(tags, nodeWindowsReservedTagName) {
+			reserved[apiv1.ResourceName(resourceName)] = *val
+		}
+	}
+	for resourceName, val := range reserved {
+		quantity, found := allocatable[resourceName]
+		if !found {
+			continue
+		}
+		quantity.Sub(val)
+		if quantity.Sign() < 0 {
+			quantity = *resource.NewQuantity(0, quantity.Format)
+		}
+		allocatable[resourceName] = quantity
+	}
+	return allocatable
+}
+
+// buildConditions returns the conditions of a simulated node: Ready by default,
+// with condition statuses overridden by the Scale Set's Tags.
+func buildConditions(tags map[string]*string) []apiv1.NodeCondition {
+	conditions := cloudprovider.BuildReadyConditions()
+	for conditionType, status := range extractConditionsFromScaleSet(tags) {
+		found := false
+		for i := range conditions {
+			if conditions[i].Type == conditionType {
+				conditions[i].Status = status
+				found = true
+			}
+		}
+		if !found {
+			conditions = append(conditions, apiv1.NodeCondition{
+				Type:               conditionType,
+				Status:             status,
+				LastTransitionTime: conditions[0].LastTransitionTime,
+			})
+		}
+	}
+	return conditions
+}
+
+func extractConditionsFromScaleSet(tags map[string]*string) map[apiv1.NodeConditionType]apiv1.ConditionStatus {
+	conditions := make(map[apiv1.NodeConditionType]apiv1.ConditionStatus)
+
+	for tagName, tagValue := range tags {
+		conditionType := strings.Split(tagName, nodeConditionTagName)
+		if len(conditionType) < 2 || conditionType[1] == "" || tagValue == nil {
+			continue
+		}
+
+		status := apiv1.ConditionStatus(*tagValue)
+		if status != apiv1.ConditionTrue && status != apiv1.ConditionFalse && status != apiv1.ConditionUnknown {
+			klog.Warningf("ignoring tag %s: invalid condition status %q", tagName, *tagValue)
+			continue
+		}
+		conditions[apiv1.NodeConditionType(conditionType[1])] = status
+	}
+
+	return conditions
+}
+
+func extractReservedResourcesFromScaleSet(tags map[string]*string, prefix string) map[string]*resource.Quantity {
+	resources := make(map[string]*resource.Quantity)
+
+	for tagName, tagValue := range tags {
+		resourceName := strings.Split(tagName, prefix)
+		if len(resourceName) < 2 || resourceName[1] == "" || tagValue == nil {
+			continue
+		}
+
+		quantity, err := resource.ParseQuantity(*tagValue)
+		if err != nil {
+			klog.Warningf("failed to parse tag %s value %q as quantity: %v", tagName, *tagValue, err)
+			continue
+		}
+		resources[resourceName[1]] = &quantity
+	}
+
+	return resources
+}
+
+func isSpotScaleSet(template compute.VirtualMachineScaleSet) bool {
+	return template.VirtualMachineProfile != nil && template.VirtualMachineProfile.Priority == compute.Spot
+}
+
+// confidentialSkuRe matches the confidential VM SKUs of the DCasv5, DCadsv5, ECasv5 and ECadsv5
+// families and their Intel counterparts, e.g. Standard_DC4as_v5.
+var confidentialSkuRe = regexp.MustCompile(`(?i)^standard_[de]c\d+[ae]d?s_v5$`)
+
+// buildSecurityType returns the security type of the scale set VMs, from their security profile, or
+// ConfidentialVM for confidential VM SKUs. It returns an empty string for standard VMs.
+func buildSecurityType(template compute.VirtualMachineScaleSet) string {
+	if template.VirtualMachineProfile != nil && template.VirtualMachineProfile.SecurityProfile != nil &&
+		template.VirtualMachineProfile.SecurityProfile.SecurityType != "" {
+		return string(template.VirtualMachineProfile.SecurityProfile.SecurityType)
+	}
+	if template.Sku != nil && template.Sku.Name != nil && confidentialSkuRe.MatchString(*template.Sku.Name) {
+		return string(compute.SecurityTypesConfidentialVM)
+	}
+	return ""
+}
+
+func hasTaint(taints []apiv1.Taint, key string) bool {
+	for _, taint := range taints {
+		if taint.Key == key {
+			return true
+		}
+	}
+	return false
+}
+
+// isScaleFromZeroDisabled returns true if the scale set is tagged to never be scaled up from zero.
+func isScaleFromZeroDisabled(tags map[string]*string) bool {
+	value, found := tags[scaleFromZeroDisabledTagName]
+	return found && value != nil && strings.EqualFold(*value, "true")
+}
+
+// decodeTagKey decodes the label, annotation, taint or resource name encoded in a tag name suffix.
+// Azure tag names can't contain '/', so it is encoded as '_', and '_' itself as "~2".
+func decodeTagKey(encoded string) string {
+	decoded := strings.Replace(encoded, "_", "/", -1)
+	return strings.Replace(decoded, "~2", "_", -1)
+}
+
+// extractLabelsFromScaleSet returns the labels set by the label tags, and by the tags with the given
+// custom prefix, if any. Labels from the built-in label tags take precedence.
+func extractLabelsFromScaleSet(tags map[string]*string, customPrefix string) map[string]string {
+	result := make(map[string]string)
+	if customPrefix != "" {
+		result = extractLabelsWithPrefix(tags, customPrefix)
+	}
+	return cloudprovider.JoinStringMaps(result, extractLabelsWithPrefix(tags, nodeLabelTagName))
+}
+
+func extractLabelsWithPrefix(tags map[string]*string, prefix string) map[string]string {
 	result := make(map[string]string)
 
 	for tagName, tagValue := range tags {
-		splits := strings.Split(tagName, nodeLabelTagName)
+		splits := strings.Split(tagName, prefix)
 		if len(splits) > 1 {
-			label := strings.Replace(splits[1], "_", "/", -1)
-			label = strings.Replace(label, "~2", "_", -1)
+			label := decodeTagKey(splits[1])
 			if label != "" {
 				result[label] = *tagValue
 			}
@@ -177,33 +494,68 @@ func extractLabelsFromScaleSet(tags map[string]*string) map[string]string {
 	return result
 }
 
+// extractAnnotationsFromScaleSet returns the annotations set on simulated nodes by the annotation tags.
+// Tags which don't decode to a valid annotation key are ignored.
+func extractAnnotationsFromScaleSet(tags map[string]*string) map[string]string {
+	result := make(map[string]string)
+
+	for tagName, tagValue := range tags {
+		if tagValue == nil || !strings.HasPrefix(tagName, nodeAnnotationTagName) {
+			continue
+		}
+		key := decodeTagKey(strings.TrimPrefix(tagName, nodeAnnotationTagName))
+		if errs := validation.IsQualifiedName(strings.ToLower(key)); len(errs) > 0 {
+			klog.Warningf("ignoring annotation tag %s: invalid annotation key %q: %s", tagName, key, strings.Join(errs, "; "))
+			continue
+		}
+		result[key] = *tagValue
+	}
+
+	return result
+}
+
 func extractTaintsFromScaleSet(tags map[string]*string) []apiv1.Taint {
 	taints := make([]apiv1.Taint, 0)
 
 	for tagName, tagValue := range tags {
-		// The tag value must be in the format <tag>:NoSchedule
-		r, _ := regexp.Compile("(.*):(?:NoSchedule|NoExecute|PreferNoSchedule)")
-
-		if r.MatchString(*tagValue) {
-			splits := strings.Split(tagName, nodeTaintTagName)
-			if len(splits) > 1 {
-				values := strings.SplitN(*tagValue, ":", 2)
-				if len(values) > 1 {
-					taintKey := strings.Replace(splits[1], "_", "/", -1)
-					taintKey = strings.Replace(taintKey, "~2", "_", -1)
-					taints = append(taints, apiv1.Taint{
-						Key:    taintKey,
-						Value:  values[0],
-						Effect: apiv1.TaintEffect(values[1]),
-					})
-				}
-			}
+		if tagValue == nil || !strings.HasPrefix(tagName, nodeTaintTagName) {
+			continue
+		}
+		splits := strings.SplitN(tagName, nodeTaintTagName, 2)
+		if splits[1] == "" {
+			continue
+		}
+		value, effect, ok := parseTaintTagValue(*tagValue)
+		if !ok {
+			klog.Warningf("Ignoring taint tag %s with invalid value %q", tagName, *tagValue)
+			continue
 		}
+		taintKey := decodeTagKey(splits[1])
+		taints = append(taints, apiv1.Taint{
+			Key:    taintKey,
+			Value:  value,
+			Effect: effect,
+		})
 	}
 
 	return taints
 }
 
+// parseTaintTagValue parses a taint tag value in the format <value>:<effect>. The
+// value may be empty or omitted entirely (<effect>), mirroring the keyless form of
+// the kubelet taint grammar, and may itself contain "=" or ":" characters.
+func parseTaintTagValue(tagValue string) (string, apiv1.TaintEffect, bool) {
+	value, effect := "", tagValue
+	if i := strings.LastIndex(tagValue, ":"); i >= 0 {
+		value, effect = tagValue[:i], tagValue[i+1:]
+	}
+	switch apiv1.TaintEffect(effect) {
+	case apiv1.TaintEffectNoSchedule, apiv1.TaintEffectNoExecute, apiv1.TaintEffectPreferNoSchedule:
+		return value, apiv1.TaintEffect(effect), true
+	}
+	return "", "", false
+}
+
 func extractAutoscalingOptionsFromScaleSetTags(tags map[string]*string) map[string]string {
 	options := make(map[string]string)
 	for tagName, tagValue := range tags {
@@ -235,6 +587,38 @@ func getFloat64Option(options map[string]string, vmssName, name string) (float64
 	return option, true
 }
 
+func getIntOption(options map[string]string, vmssName, name string) (int, bool) {
+	raw, ok := options[strings.ToLower(name)]
+	if !ok {
+		return 0, false
+	}
+
+	option, err := strconv.Atoi(raw)
+	if err != nil {
+		klog.Warningf("failed to convert VMSS %q tag %s_%s value %q to int: %v",
+			vmssName, nodeOptionsTagName, name, raw, err)
+		return 0, false
+	}
+
+	return option, true
+}
+
+func getBoolOption(options map[string]string, vmssName, name string) (bool, bool) {
+	raw, ok := options[strings.ToLower(name)]
+	if !ok {
+		return false, false
+	}
+
+	option, err := strconv.ParseBool(raw)
+	if err != nil {
+		klog.Warningf("failed to convert VMSS %q tag %s_%s value %q to bool: %v",
+			vmssName, nodeOptionsTagName, name, raw, err)
+		return false, false
+	}
+
+	return option, true
+}
+
 func getDurationOption(options map[string]string, vmssName, name string) (time.Duration, bool) {
 	raw, ok := options[strings.ToLower(name)]
 	if !ok {
@@ -256,14 +640,20 @@ func extractAllocatableResourcesFromScaleSet(tags map[string]*string) map[string
 
 	for tagName, tagValue := range tags {
 		resourceName := strings.Split(tagName, nodeResourcesTagName)
-		if len(resourceName) < 2 || resourceName[1] == "" {
+		if len(resourceName) < 2 || resourceName[1] == "" || tagValue == nil {
 			continue
 		}
 
-		normalizedResourceName := strings.Replace(resourceName[1], "_", "/", -1)
-		normalizedResourceName = strings.Replace(normalizedResourceName, "~2", "/", -1)
+		normalizedResourceName := decodeTagKey(resourceName[1])
+		if errs := validation.IsQualifiedName(normalizedResourceName); len(errs) > 0 {
+			klog.Warningf("ignoring resource tag %s: invalid resource name %q: %s", tagName, normalizedResourceName, strings.Join(errs, "; "))
+			registerInvalidResourceTag(invalidResourceTagName)
+			continue
+		}
 		quantity, err := resource.ParseQuantity(*tagValue)
 		if err != nil {
+			klog.Warningf("ignoring resource tag %s: invalid quantity %q: %v", tagName, *tagValue, err)
+			registerInvalidResourceTag(invalidResourceTagQuantity)
 			continue
 		}
 		resources[normalizedResourceName] = &quantity
@@ -272,6 +662,148 @@ func extractAllocatableResourcesFromScaleSet(tags map[string]*string) map[string
 	return resources
 }
 
+// buildEphemeralStorage returns the size in bytes of the disk backing the ephemeral storage
+// of nodes: the OS disk by default, or the SKU's temp disk or the first data disk if selected
+// by the ephemeral-storage-source tag. Sizes only known from the SKU API are looked up when
+// the dynamic instance list is enabled.
+func buildEphemeralStorage(template compute.VirtualMachineScaleSet, manager *AzureManager) (int64, bool) {
+	source := ephemeralStorageSourceOS
+	if value, found := template.Tags[nodeEphemeralStorageSourceTagName]; found && value != nil {
+		source = strings.ToLower(*value)
+	}
+
+	var storageProfile *compute.VirtualMachineScaleSetStorageProfile
+	if template.VirtualMachineScaleSetProperties != nil && template.VirtualMachineProfile != nil {
+		storageProfile = template.VirtualMachineProfile.StorageProfile
+	}
+
+	switch source {
+	case ephemeralStorageSourceOS:
+		if storageProfile == nil || storageProfile.OsDisk == nil {
+			return 0, false
+		}
+		osDisk := storageProfile.OsDisk
+		if osDisk.DiskSizeGB != nil {
+			return int64(*osDisk.DiskSizeGB) * 1024 * 1024 * 1024, true
+		}
+		// Ephemeral OS disks default to the size of the disk they are placed on.
+		if osDisk.DiffDiskSettings != nil && osDisk.DiffDiskSettings.Option == compute.Local {
+			if osDisk.DiffDiskSettings.Placement == compute.ResourceDisk {
+				return getTempDiskSize(template, manager)
+			}
+			return getCacheDiskSize(template, manager)
+		}
+		return 0, false
+	case ephemeralStorageSourceTemp:
+		return getTempDiskSize(template, manager)
+	case ephemeralStorageSourceData:
+		if storageProfile == nil || storageProfile.DataDisks == nil {
+			return 0, false
+		}
+		var dataDisk *compute.VirtualMachineScaleSetDataDisk
+		for i, disk := range *storageProfile.DataDisks {
+			if disk.DiskSizeGB == nil || disk.Lun == nil {
+				continue
+			}
+			if dataDisk == nil || *disk.Lun < *dataDisk.Lun {
+				dataDisk = &(*storageProfile.DataDisks)[i]
+			}
+		}
+		if dataDisk == nil {
+			return 0, false
+		}
+		return int64(*dataDisk.DiskSizeGB) * 1024 * 1024 * 1024, true
+	default:
+		klog.Warningf("Unknown ephemeral storage source %q for SKU %s", source, *template.Sku.Name)
+		return 0, false
+	}
+}
+
+// getTempDiskSize returns the size in bytes of the SKU's temp disk.
+func getTempDiskSize(template compute.VirtualMachineScaleSet, manager *AzureManager) (int64, bool) {
+	sku, found := getSKUForEphemeralStorage(template, manager)
+	if !found {
+		return 0, false
+	}
+	sizeMb, err := sku.MaxResourceVolumeMB()
+	if err != nil || sizeMb <= 0 {
+		klog.V(4).Infof("No temp disk size for SKU %s: %v", *template.Sku.Name, err)
+		return 0, false
+	}
+	return sizeMb * 1024 * 1024, true
+}
+
+// getCacheDiskSize returns the size in bytes of the SKU's cache disk.
+func getCacheDiskSize(template compute.VirtualMachineScaleSet, manager *AzureManager) (int64, bool) {
+	sku, found := getSKUForEphemeralStorage(template, manager)
+	if !found {
+		return 0, false
+	}
+	size, err := sku.MaxCachedDiskBytes()
+	if err != nil || size <= 0 {
+		klog.V(4).Infof("No cache disk size for SKU %s: %v", *template.Sku.Name, err)
+		return 0, false
+	}
+	return size, true
+}
+
+func getSKUForEphemeralStorage(template compute.VirtualMachineScaleSet, manager *AzureManager) (skewer.SKU, bool) {
+	if !manager.config.EnableDynamicInstanceList || manager.azureCache == nil {
+		return skewer.SKU{}, false
+	}
+	sku, err := manager.azureCache.GetSKU(context.Background(), *template.Sku.Name, *template.Location)
+	if err != nil {
+		klog.V(1).Infof("Failed to get SKU %s for ephemeral storage: %v", *template.Sku.Name, err)
+		return skewer.SKU{}, false
+	}
+	return sku, true
+}
+
+// instanceTypeDelta is a field of an instance type for which the dynamic SKU
+// information and the static list disagree.
+type instanceTypeDelta struct {
+	field   string
+	dynamic int64
+	static  int64
+}
+
+// compareInstanceTypes returns the fields of an instance type for which the dynamic
+// SKU information and the static list disagree.
+func compareInstanceTypes(dynamic, static InstanceType) []instanceTypeDelta {
+	var deltas []instanceTypeDelta
+	if dynamic.VCPU != static.VCPU {
+		deltas = append(deltas, instanceTypeDelta{field: "vcpu", dynamic: dynamic.VCPU, static: static.VCPU})
+	}
+	if dynamic.MemoryMb != static.MemoryMb {
+		deltas = append(deltas, instanceTypeDelta{field: "memoryMb", dynamic: dynamic.MemoryMb, static: static.MemoryMb})
+	}
+	if dynamic.GPU != static.GPU {
+		deltas = append(deltas, instanceTypeDelta{field: "gpu", dynamic: dynamic.GPU, static: static.GPU})
+	}
+	return deltas
+}
+
+// reportInstanceTypeDeltas logs and counts each disagreement on a SKU field once, as
+// templates are rebuilt for every node group using the SKU on every loop.
+func reportInstanceTypeDeltas(azCache *azureCache, skuName string, deltas []instanceTypeDelta) {
+	for _, delta := range deltas {
+		if azCache.markSkuDisagreementReported(skuName, delta.field) {
+			continue
+		}
+		klog.V(1).Infof("SKU API and static list disagree on %s for SKU %s: dynamic %d, static %d",
+			delta.field, skuName, delta.dynamic, delta.static)
+		registerSkuDisagreement(skuName, delta.field)
+	}
+}
+
+// fpgaResourceName returns the name of the FPGA resource of NP-series template nodes.
+func fpgaResourceName(config *Config) apiv1.ResourceName {
+	if config.FPGAResourceName != "" {
+		return apiv1.ResourceName(config.FPGAResourceName)
+	}
+	return defaultFPGAResourceName
+}
+
 // isNPSeries returns if a SKU is an NP-series SKU
 // SKU API reports GPUs for NP-series but it's actually FPGAs
 func isNPSeries(name string) bool {
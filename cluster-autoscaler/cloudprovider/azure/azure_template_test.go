@@ -17,11 +17,18 @@ limitations under the License.
 package azure
 
 import (
+	"flag"
 	"fmt"
+	skucompute "github.com/Azure/azure-sdk-for-go/services/compute/mgmt/2019-07-01/compute"
+	"github.com/Azure/azure-sdk-for-go/services/compute/mgmt/2022-08-01/compute"
 	"github.com/Azure/go-autorest/autorest/to"
 	"github.com/stretchr/testify/assert"
 	apiv1 "k8s.io/api/core/v1"
 	"k8s.io/apimachinery/pkg/api/resource"
+	"k8s.io/autoscaler/cluster-autoscaler/utils/gpu"
+	"k8s.io/klog/v2"
+	"math"
+	"sync"
 	"testing"
 )
 
@@ -47,13 +54,57 @@ func TestExtractLabelsFromScaleSet(t *testing.T) {
 		fmt.Sprintf("%s%s", nodeLabelTagName, escapedUnderscoreNodeLabelKey): &escapedUnderscoreNodeLabelValue,
 	}
 
-	labels := extractLabelsFromScaleSet(tags)
+	labels := extractLabelsFromScaleSet(tags, "")
 	assert.Len(t, labels, 3)
 	assert.Equal(t, expectedNodeLabelValue, labels[expectedNodeLabelKey])
 	assert.Equal(t, escapedSlashNodeLabelValue, labels[expectedSlashEscapedNodeLabelKey])
 	assert.Equal(t, escapedUnderscoreNodeLabelValue, labels[expectedUnderscoreEscapedNodeLabelKey])
 }
 
+func TestExtractAnnotationsFromScaleSet(t *testing.T) {
+	tags := map[string]*string{
+		fmt.Sprintf("%s%s", nodeAnnotationTagName, "foo"):                    to.StringPtr("bar"),
+		fmt.Sprintf("%s%s", nodeAnnotationTagName, "example.com_owner"):      to.StringPtr("team-a"),
+		fmt.Sprintf("%s%s", nodeAnnotationTagName, "example.com_cost~2unit"): to.StringPtr("42"),
+		fmt.Sprintf("%s%s", nodeAnnotationTagName, "in valid"):               to.StringPtr("ignored"),
+		fmt.Sprintf("%s%s", nodeAnnotationTagName, ""):                       to.StringPtr("ignored"),
+		fmt.Sprintf("%s%s", nodeLabelTagName, "foo"):                         to.StringPtr("label"),
+	}
+
+	annotations := extractAnnotationsFromScaleSet(tags)
+	assert.Equal(t, map[string]string{
+		"foo":                   "bar",
+		"example.com/owner":     "team-a",
+		"example.com/cost_unit": "42",
+	}, annotations)
+}
+
+func TestExtractLabelsFromScaleSetCustomPrefix(t *testing.T) {
+	tags := map[string]*string{
+		fmt.Sprintf("%s%s", nodeLabelTagName, "foo"):    to.StringPtr("builtin"),
+		fmt.Sprintf("%s%s", nodeLabelTagName, "shared"): to.StringPtr("builtin"),
+		"myorg_label_bar":                to.StringPtr("custom"),
+		"myorg_label_example.com_shared": to.StringPtr("custom"),
+		"myorg_label_shared":             to.StringPtr("custom"),
+	}
+
+	assert.Equal(t, map[string]string{"foo": "builtin", "shared": "builtin"}, extractLabelsFromScaleSet(tags, ""))
+	assert.Equal(t, map[string]string{
+		"foo":                "builtin",
+		"shared":             "builtin",
+		"bar":                "custom",
+		"example.com/shared": "custom",
+	}, extractLabelsFromScaleSet(tags, "myorg_label_"))
+
+	stubStaticInstanceType(t, &InstanceType{VCPU: 4, MemoryMb: 16384})
+	manager := &AzureManager{config: &Config{NodeLabelTagPrefix: "myorg_label_"}}
+	node, err := buildNodeFromTemplate("labels", newTestTemplate(false, tags), manager)
+	assert.NoError(t, err)
+	assert.Equal(t, "custom", node.Labels["bar"])
+	assert.Equal(t, "builtin", node.Labels["shared"])
+	assert.NotContains(t, node.Labels, "myorg_label_bar")
+}
+
 func TestExtractTaintsFromScaleSet(t *testing.T) {
 	noScheduleTaintValue := "foo:NoSchedule"
 	noExecuteTaintValue := "bar:NoExecute"
@@ -100,6 +151,37 @@ func TestExtractTaintsFromScaleSet(t *testing.T) {
 	assert.Equal(t, makeTaintSet(expectedTaints), makeTaintSet(taints))
 }
 
+func TestParseTaintTagValue(t *testing.T) {
+	testCases := []struct {
+		name           string
+		tagValue       string
+		expectedValue  string
+		expectedEffect apiv1.TaintEffect
+		expectedOk     bool
+	}{
+		{name: "NoSchedule", tagValue: "foo:NoSchedule", expectedValue: "foo", expectedEffect: apiv1.TaintEffectNoSchedule, expectedOk: true},
+		{name: "NoExecute", tagValue: "foo:NoExecute", expectedValue: "foo", expectedEffect: apiv1.TaintEffectNoExecute, expectedOk: true},
+		{name: "PreferNoSchedule", tagValue: "foo:PreferNoSchedule", expectedValue: "foo", expectedEffect: apiv1.TaintEffectPreferNoSchedule, expectedOk: true},
+		{name: "empty value", tagValue: ":NoSchedule", expectedValue: "", expectedEffect: apiv1.TaintEffectNoSchedule, expectedOk: true},
+		{name: "no value", tagValue: "PreferNoSchedule", expectedValue: "", expectedEffect: apiv1.TaintEffectPreferNoSchedule, expectedOk: true},
+		{name: "value containing =", tagValue: "a=b:NoExecute", expectedValue: "a=b", expectedEffect: apiv1.TaintEffectNoExecute, expectedOk: true},
+		{name: "value containing :", tagValue: "a:b:NoSchedule", expectedValue: "a:b", expectedEffect: apiv1.TaintEffectNoSchedule, expectedOk: true},
+		{name: "unknown effect", tagValue: "foo:NoScheduleNow", expectedOk: false},
+		{name: "lowercase effect", tagValue: "foo:noschedule", expectedOk: false},
+		{name: "missing effect", tagValue: "foo:", expectedOk: false},
+		{name: "blank", tagValue: "", expectedOk: false},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			value, effect, ok := parseTaintTagValue(tc.tagValue)
+			assert.Equal(t, tc.expectedOk, ok)
+			assert.Equal(t, tc.expectedValue, value)
+			assert.Equal(t, tc.expectedEffect, effect)
+		})
+	}
+}
+
 func TestExtractAllocatableResourcesFromScaleSet(t *testing.T) {
 	tags := map[string]*string{
 		fmt.Sprintf("%s%s", nodeResourcesTagName, "cpu"):                        to.StringPtr("100m"),
@@ -119,6 +201,925 @@ func TestExtractAllocatableResourcesFromScaleSet(t *testing.T) {
 	assert.Equal(t, (&exepectedCustomAllocatable).String(), labels["nvidia.com/Tesla-P100-PCIE"].String())
 }
 
+func TestDecodeTagKey(t *testing.T) {
+	assert.Equal(t, "a_b/c", decodeTagKey("a~2b_c"))
+
+	value := "1"
+	tags := map[string]*string{
+		fmt.Sprintf("%s%s", nodeLabelTagName, "example.com_a~2b"):     &value,
+		fmt.Sprintf("%s%s", nodeTaintTagName, "example.com_a~2b"):     to.StringPtr("NoSchedule"),
+		fmt.Sprintf("%s%s", nodeResourcesTagName, "example.com_a~2b"): &value,
+	}
+	assert.Contains(t, extractLabelsFromScaleSet(tags, ""), "example.com/a_b")
+	assert.Equal(t, "example.com/a_b", extractTaintsFromScaleSet(tags)[0].Key)
+	assert.Contains(t, extractAllocatableResourcesFromScaleSet(tags), "example.com/a_b")
+}
+
+func TestExtractAllocatableResourcesFromScaleSetInvalidTags(t *testing.T) {
+	invalidNames := invalidResourceTags(t, invalidResourceTagName)
+	invalidQuantities := invalidResourceTags(t, invalidResourceTagQuantity)
+	tags := map[string]*string{
+		fmt.Sprintf("%s%s", nodeResourcesTagName, "example.com_valid"):    to.StringPtr("2"),
+		fmt.Sprintf("%s%s", nodeResourcesTagName, "example.com_in valid"): to.StringPtr("2"),
+		fmt.Sprintf("%s%s", nodeResourcesTagName, "foo__bar"):             to.StringPtr("2"),
+		fmt.Sprintf("%s%s", nodeResourcesTagName, "example.com_nil"):      nil,
+		fmt.Sprintf("%s%s", nodeResourcesTagName, "example.com_quantity"): to.StringPtr("two"),
+	}
+
+	resources := extractAllocatableResourcesFromScaleSet(tags)
+
+	assert.Len(t, resources, 1)
+	assert.Equal(t, int64(2), resources["example.com/valid"].Value())
+	assert.NotContains(t, resources, "foo//bar")
+	assert.Equal(t, invalidNames+2, invalidResourceTags(t, invalidResourceTagName))
+	assert.Equal(t, invalidQuantities+1, invalidResourceTags(t, invalidResourceTagQuantity))
+}
+
+func TestBuildNodeFromTemplateAllocatable(t *testing.T) {
+	stubStaticInstanceType(t, &InstanceType{VCPU: 4, MemoryMb: 16384})
+	manager := &AzureManager{config: &Config{}}
+
+	linuxNode, err := buildNodeFromTemplate("linux", newTestTemplate(false, nil), manager)
+	assert.NoError(t, err)
+	windowsNode, err := buildNodeFromTemplate("windows", newTestTemplate(true, nil), manager)
+	assert.NoError(t, err)
+
+	assert.Equal(t, linuxNode.Status.Capacity, linuxNode.Status.Allocatable)
+	assert.Equal(t, int64(defaultLinuxMaxPods), linuxNode.Status.Allocatable.Pods().Value())
+	assert.Equal(t, int64(defaultWindowsMaxPods), windowsNode.Status.Allocatable.Pods().Value())
+	assert.Equal(t, 1, linuxNode.Status.Allocatable.Cpu().Cmp(*windowsNode.Status.Allocatable.Cpu()))
+	assert.Equal(t, 1, linuxNode.Status.Allocatable.Memory().Cmp(*windowsNode.Status.Allocatable.Memory()))
+	assert.Equal(t, linuxNode.Status.Capacity.Memory().Value(), windowsNode.Status.Capacity.Memory().Value())
+
+	t.Run("reservations overridden by tags", func(t *testing.T) {
+		tags := map[string]*string{
+			fmt.Sprintf("%s%s", nodeWindowsReservedTagName, "cpu"):    to.StringPtr("1"),
+			fmt.Sprintf("%s%s", nodeWindowsReservedTagName, "memory"): to.StringPtr("4Gi"),
+		}
+		node, err := buildNodeFromTemplate("windows", newTestTemplate(true, tags), manager)
+		assert.NoError(t, err)
+		expectedCPU := resource.MustParse("3")
+		assert.Equal(t, 0, expectedCPU.Cmp(*node.Status.Allocatable.Cpu()))
+		expectedMemory := resource.MustParse("12Gi")
+		assert.Equal(t, expectedMemory.Value(), node.Status.Allocatable.Memory().Value())
+	})
+
+	t.Run("kubelet reservations", func(t *testing.T) {
+		tags := map[string]*string{
+			fmt.Sprintf("%s%s", nodeKubeReservedTagName, "cpu"):      to.StringPtr("200m"),
+			fmt.Sprintf("%s%s", nodeKubeReservedTagName, "memory"):   to.StringPtr("1Gi"),
+			fmt.Sprintf("%s%s", nodeSystemReservedTagName, "memory"): to.StringPtr("512Mi"),
+			fmt.Sprintf("%s%s", nodeWindowsReservedTagName, "cpu"):   to.StringPtr("1"),
+		}
+		for _, windows := range []bool{false, true} {
+			node, err := buildNodeFromTemplate("reserved", newTestTemplate(windows, tags), manager)
+			assert.NoError(t, err)
+			expectedCPU := resource.MustParse("3800m")
+			assert.Equal(t, 0, expectedCPU.Cmp(*node.Status.Allocatable.Cpu()))
+			expectedMemory := resource.MustParse("14848Mi")
+			assert.Equal(t, expectedMemory.Value(), node.Status.Allocatable.Memory().Value())
+			assert.Equal(t, linuxNode.Status.Capacity.Memory().Value(), node.Status.Capacity.Memory().Value())
+		}
+	})
+}
+
+func TestBuildGenericLabelsZones(t *testing.T) {
+	testCases := []struct {
+		name         string
+		location     string
+		zones        *[]string
+		expectedZone string
+		expectedDisk string
+	}{
+		{
+			name:         "zero zones",
+			zones:        nil,
+			expectedZone: "0",
+			expectedDisk: "",
+		},
+		{
+			name:         "padded location",
+			location:     " EastUS\t",
+			zones:        &[]string{"1"},
+			expectedZone: "eastus-1",
+			expectedDisk: "eastus-1",
+		},
+		{
+			name:         "single zone",
+			zones:        &[]string{"2"},
+			expectedZone: "eastus-2",
+			expectedDisk: "eastus-2",
+		},
+		{
+			name:         "multiple zones",
+			zones:        &[]string{"1", "2", "3"},
+			expectedZone: "eastus-1__eastus-2__eastus-3",
+			expectedDisk: "eastus-1__eastus-2__eastus-3",
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			template := newTestTemplate(false, nil)
+			template.Location = to.StringPtr("EastUS")
+			if tc.location != "" {
+				template.Location = to.StringPtr(tc.location)
+			}
+			template.Zones = tc.zones
+			labels := buildGenericLabels(template, "node")
+			assert.Equal(t, tc.expectedZone, labels[apiv1.LabelTopologyZone])
+			assert.Equal(t, tc.expectedDisk, labels[azureDiskTopologyKey])
+			assert.Equal(t, "eastus", labels[apiv1.LabelTopologyRegion])
+		})
+	}
+}
+
+func TestBuildNodeFromTemplateNoLocation(t *testing.T) {
+	manager := &AzureManager{config: &Config{}}
+	for _, location := range []*string{nil, to.StringPtr(" ")} {
+		template := newTestTemplate(false, nil)
+		template.Location = location
+		_, err := buildNodeFromTemplate("no-location", template, manager)
+		assert.Error(t, err)
+	}
+}
+
+func TestBuildNodeFromTemplateNoSkuName(t *testing.T) {
+	manager := &AzureManager{config: &Config{EnableDynamicInstanceList: true}}
+	for _, sku := range []*compute.Sku{nil, {Capacity: to.Int64Ptr(1)}} {
+		template := newTestTemplate(false, nil)
+		template.Sku = sku
+		_, err := buildNodeFromTemplate("no-sku", template, manager)
+		assert.Error(t, err)
+		_, err = GetVMSSTypeStatically(template)
+		assert.Error(t, err)
+		_, err = GetVMSSTypeDynamically(template, nil)
+		assert.Error(t, err)
+	}
+}
+
+func TestBuildNodeFromTemplateInvalidMemory(t *testing.T) {
+	manager := &AzureManager{config: &Config{}}
+	for _, memoryMb := range []int64{-1, math.MaxInt64/(1024*1024) + 1, math.MaxInt64} {
+		stubStaticInstanceType(t, &InstanceType{VCPU: 4, MemoryMb: memoryMb})
+		_, err := buildNodeFromTemplate("invalid-memory", newTestTemplate(false, nil), manager)
+		assert.Error(t, err, "memoryMb %d", memoryMb)
+	}
+}
+
+func TestBuildNodeFromTemplateStructuredLogs(t *testing.T) {
+	stubStaticInstanceType(t, &InstanceType{VCPU: 4, MemoryMb: 16384})
+	manager := &AzureManager{config: &Config{}}
+
+	flags := flag.NewFlagSet("klog", flag.ContinueOnError)
+	klog.InitFlags(flags)
+	verbosity := flags.Lookup("v").Value.String()
+	assert.NoError(t, flags.Set("v", "4"))
+	defer flags.Set("v", verbosity)
+	sink := &recordingLogSink{}
+	klog.SetLogger(klog.New(sink))
+	defer klog.ClearLogger()
+
+	_, err := buildNodeFromTemplate("logged", newTestTemplate(false, nil), manager)
+	assert.NoError(t, err)
+	assert.Equal(t, map[string]interface{}{
+		"nodeGroupName": "logged",
+		"sku":           "Standard_D4s_v3",
+		"source":        "static",
+		"vcpu":          int64(4),
+		"memoryMb":      int64(16384),
+		"gpu":           int64(0),
+	}, sink.keysAndValues("Resolved template node SKU"))
+	allocatable := sink.keysAndValues("Computed template node allocatable")
+	assert.Equal(t, "logged", allocatable["nodeGroupName"])
+	assert.Equal(t, "4", allocatable["cpu"])
+}
+
+// recordingLogSink is a log sink recording the key/value pairs of the info messages it receives.
+type recordingLogSink struct {
+	mu       sync.Mutex
+	messages map[string]map[string]interface{}
+}
+
+func (s *recordingLogSink) Init(klog.RuntimeInfo) {}
+
+func (s *recordingLogSink) Enabled(int) bool { return true }
+
+func (s *recordingLogSink) Info(_ int, msg string, keysAndValues ...interface{}) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.messages == nil {
+		s.messages = make(map[string]map[string]interface{})
+	}
+	values := make(map[string]interface{})
+	for i := 0; i+1 < len(keysAndValues); i += 2 {
+		values[fmt.Sprint(keysAndValues[i])] = keysAndValues[i+1]
+	}
+	s.messages[msg] = values
+}
+
+func (s *recordingLogSink) Error(error, string, ...interface{}) {}
+
+func (s *recordingLogSink) WithValues(...interface{}) klog.LogSink { return s }
+
+func (s *recordingLogSink) WithName(string) klog.LogSink { return s }
+
+// keysAndValues returns the key/value pairs of the last info message logged with the given text.
+func (s *recordingLogSink) keysAndValues(msg string) map[string]interface{} {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.messages[msg]
+}
+
+func TestBuildNodeFromTemplateMIGResources(t *testing.T) {
+	stubStaticInstanceType(t, &InstanceType{VCPU: 96, MemoryMb: 917504, GPU: 1})
+	manager := &AzureManager{config: &Config{}}
+
+	testCases := []struct {
+		name          string
+		tags          map[string]*string
+		expectedGPUs  int64
+		expectedSlice int64
+	}{
+		{
+			name:         "sku gpus",
+			expectedGPUs: 1,
+		},
+		{
+			name: "single strategy",
+			tags: map[string]*string{
+				fmt.Sprintf("%s%s", nodeResourcesTagName, "nvidia.com_gpu"): to.StringPtr("7"),
+			},
+			expectedGPUs: 7,
+		},
+		{
+			name: "mixed strategy",
+			tags: map[string]*string{
+				fmt.Sprintf("%s%s", nodeResourcesTagName, "nvidia.com_mig-1g.10gb"): to.StringPtr("7"),
+			},
+			expectedGPUs:  1,
+			expectedSlice: 7,
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			template := newTestTemplate(false, tc.tags)
+			template.Sku.Name = to.StringPtr("Standard_NC24ads_A100_v4")
+			node, err := buildNodeFromTemplate("mig", template, manager)
+			assert.NoError(t, err)
+			gpus := node.Status.Allocatable[gpu.ResourceNvidiaGPU]
+			assert.Equal(t, tc.expectedGPUs, gpus.Value())
+			slices := node.Status.Allocatable["nvidia.com/mig-1g.10gb"]
+			assert.Equal(t, tc.expectedSlice, slices.Value())
+		})
+	}
+}
+
+func TestBuildNodeFromTemplateSpot(t *testing.T) {
+	stubStaticInstanceType(t, &InstanceType{VCPU: 4, MemoryMb: 16384})
+	manager := &AzureManager{config: &Config{}}
+	spotTaint := apiv1.Taint{Key: scaleSetPriorityLabelKey, Value: scaleSetPrioritySpot, Effect: apiv1.TaintEffectNoSchedule}
+
+	template := newTestTemplate(false, nil)
+	node, err := buildNodeFromTemplate("regular", template, manager)
+	assert.NoError(t, err)
+	assert.NotContains(t, node.Labels, scaleSetPriorityLabelKey)
+	assert.Empty(t, node.Spec.Taints)
+
+	template.VirtualMachineProfile.Priority = compute.Spot
+	node, err = buildNodeFromTemplate("spot", template, manager)
+	assert.NoError(t, err)
+	assert.Equal(t, scaleSetPrioritySpot, node.Labels[scaleSetPriorityLabelKey])
+	assert.Equal(t, []apiv1.Taint{spotTaint}, node.Spec.Taints)
+
+	t.Run("explicit taint from tags is kept", func(t *testing.T) {
+		template.Tags = map[string]*string{
+			fmt.Sprintf("%s%s", nodeTaintTagName, "kubernetes.azure.com_scalesetpriority"): to.StringPtr("spot:PreferNoSchedule"),
+		}
+		node, err := buildNodeFromTemplate("spot", template, manager)
+		assert.NoError(t, err)
+		assert.Equal(t, []apiv1.Taint{{Key: scaleSetPriorityLabelKey, Value: scaleSetPrioritySpot, Effect: apiv1.TaintEffectPreferNoSchedule}}, node.Spec.Taints)
+	})
+}
+
+func TestBuildNodeFromTemplatePodsCapacitySource(t *testing.T) {
+	stubStaticInstanceType(t, &InstanceType{VCPU: 4, MemoryMb: 16384})
+	manager := &AzureManager{config: &Config{}}
+
+	testCases := []struct {
+		name           string
+		windows        bool
+		tags           map[string]*string
+		expectedPods   int64
+		expectedSource string
+	}{
+		{
+			name:           "linux",
+			expectedPods:   defaultLinuxMaxPods,
+			expectedSource: podsCapacitySourceFallback,
+		},
+		{
+			name:           "windows",
+			windows:        true,
+			expectedPods:   defaultWindowsMaxPods,
+			expectedSource: podsCapacitySourceOSDefault,
+		},
+		{
+			name:           "pods resource tag",
+			windows:        true,
+			tags:           map[string]*string{fmt.Sprintf("%s%s", nodeResourcesTagName, "pods"): to.StringPtr("50")},
+			expectedPods:   50,
+			expectedSource: podsCapacitySourceTag,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			node, err := buildNodeFromTemplate("pods", newTestTemplate(tc.windows, tc.tags), manager)
+			assert.NoError(t, err)
+			assert.Equal(t, tc.expectedPods, node.Status.Capacity.Pods().Value())
+			assert.Equal(t, tc.expectedSource, node.Annotations[podsCapacitySourceAnnotation])
+		})
+	}
+}
+
+func TestTemplateNodeMaxPods(t *testing.T) {
+	podsTag := fmt.Sprintf("%s%s", nodeResourcesTagName, "pods")
+	testCases := []struct {
+		name           string
+		windows        bool
+		tags           map[string]*string
+		expectedPods   int64
+		expectedSource string
+	}{
+		{
+			name:           "linux default",
+			expectedPods:   defaultLinuxMaxPods,
+			expectedSource: podsCapacitySourceFallback,
+		},
+		{
+			name:           "windows default",
+			windows:        true,
+			expectedPods:   defaultWindowsMaxPods,
+			expectedSource: podsCapacitySourceOSDefault,
+		},
+		{
+			name:           "overridden by tag",
+			windows:        true,
+			tags:           map[string]*string{podsTag: to.StringPtr("250")},
+			expectedPods:   250,
+			expectedSource: podsCapacitySourceTag,
+		},
+		{
+			name:           "invalid tag",
+			tags:           map[string]*string{podsTag: to.StringPtr("many")},
+			expectedPods:   defaultLinuxMaxPods,
+			expectedSource: podsCapacitySourceFallback,
+		},
+		{
+			name:           "kubenet default",
+			tags:           map[string]*string{nodeNetworkPluginTagName: to.StringPtr("kubenet")},
+			expectedPods:   110,
+			expectedSource: podsCapacitySourceNetworkPlugin,
+		},
+		{
+			name:           "azure cni default",
+			tags:           map[string]*string{nodeNetworkPluginTagName: to.StringPtr("Azure")},
+			expectedPods:   30,
+			expectedSource: podsCapacitySourceNetworkPlugin,
+		},
+		{
+			name:           "azure cni overlay default on windows",
+			windows:        true,
+			tags:           map[string]*string{nodeNetworkPluginTagName: to.StringPtr("azure-overlay")},
+			expectedPods:   250,
+			expectedSource: podsCapacitySourceNetworkPlugin,
+		},
+		{
+			name: "pods tag over network plugin",
+			tags: map[string]*string{
+				nodeNetworkPluginTagName: to.StringPtr("azure"),
+				podsTag:                  to.StringPtr("50"),
+			},
+			expectedPods:   50,
+			expectedSource: podsCapacitySourceTag,
+		},
+		{
+			name:           "unknown network plugin",
+			tags:           map[string]*string{nodeNetworkPluginTagName: to.StringPtr("calico")},
+			expectedPods:   defaultLinuxMaxPods,
+			expectedSource: podsCapacitySourceFallback,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			pods, source := TemplateNodeMaxPods(newTestTemplate(tc.windows, tc.tags))
+			assert.Equal(t, tc.expectedPods, pods)
+			assert.Equal(t, tc.expectedSource, source)
+		})
+	}
+}
+
+func TestBuildNodeFromTemplateSkipsTemplateTagsAsLabels(t *testing.T) {
+	stubStaticInstanceType(t, &InstanceType{VCPU: 4, MemoryMb: 16384})
+	manager := &AzureManager{config: &Config{}}
+
+	labelTag := fmt.Sprintf("%s%s", nodeLabelTagName, "foo")
+	taintTag := fmt.Sprintf("%s%s", nodeTaintTagName, "dedicated")
+	resourceTag := fmt.Sprintf("%s%s", nodeResourcesTagName, "example.com_widget")
+	tags := map[string]*string{
+		labelTag:                     to.StringPtr("bar"),
+		taintTag:                     to.StringPtr("reserved:NoSchedule"),
+		resourceTag:                  to.StringPtr("2"),
+		scaleFromZeroDisabledTagName: to.StringPtr("false"),
+		"poolName":                   to.StringPtr("pool1"),
+	}
+	node, err := buildNodeFromTemplate("labels", newTestTemplate(false, tags), manager)
+	assert.NoError(t, err)
+
+	assert.NotContains(t, node.Labels, labelTag)
+	assert.NotContains(t, node.Labels, taintTag)
+	assert.NotContains(t, node.Labels, resourceTag)
+	assert.NotContains(t, node.Labels, scaleFromZeroDisabledTagName)
+	assert.Equal(t, "bar", node.Labels["foo"])
+	assert.Equal(t, "pool1", node.Labels["poolName"])
+	assert.Equal(t, []apiv1.Taint{{Key: "dedicated", Value: "reserved", Effect: apiv1.TaintEffectNoSchedule}}, node.Spec.Taints)
+	widgets := node.Status.Capacity[apiv1.ResourceName("example.com/widget")]
+	assert.Equal(t, int64(2), widgets.Value())
+}
+
+func TestBuildNodeFromTemplateDynamicInstanceListFallback(t *testing.T) {
+	staticFunc := GetVMSSTypeStatically
+	dynamicFunc := GetVMSSTypeDynamically
+	defer func() {
+		GetVMSSTypeStatically = staticFunc
+		GetVMSSTypeDynamically = dynamicFunc
+	}()
+	GetVMSSTypeStatically = func(template compute.VirtualMachineScaleSet) (*InstanceType, error) {
+		return nil, fmt.Errorf("instance type %q not supported", *template.Sku.Name)
+	}
+	dynamicCalls := 0
+	GetVMSSTypeDynamically = func(template compute.VirtualMachineScaleSet, azCache *azureCache) (InstanceType, error) {
+		dynamicCalls++
+		return InstanceType{VCPU: 8, MemoryMb: 32768}, nil
+	}
+
+	testCases := []struct {
+		name                 string
+		fallback             bool
+		expectedErr          bool
+		expectedDynamicCalls int
+	}{
+		{
+			name:        "SKU missing from the static list without fallback",
+			expectedErr: true,
+		},
+		{
+			name:                 "SKU missing from the static list looked up with the SKU API",
+			fallback:             true,
+			expectedDynamicCalls: 1,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			dynamicCalls = 0
+			manager := &AzureManager{config: &Config{EnableDynamicInstanceListFallback: tc.fallback}}
+			node, err := buildNodeFromTemplate("new-sku", newTestTemplate(false, nil), manager)
+			assert.Equal(t, tc.expectedDynamicCalls, dynamicCalls)
+			if tc.expectedErr {
+				assert.Error(t, err)
+				return
+			}
+			assert.NoError(t, err)
+			assert.Equal(t, int64(8), node.Status.Capacity.Cpu().Value())
+		})
+	}
+}
+
+func TestBuildNodeFromTemplateSecurityType(t *testing.T) {
+	stubStaticInstanceType(t, &InstanceType{VCPU: 4, MemoryMb: 16384})
+	manager := &AzureManager{config: &Config{}}
+
+	testCases := []struct {
+		name                 string
+		sku                  string
+		securityType         compute.SecurityTypes
+		tags                 map[string]*string
+		expectedSecurityType string
+	}{
+		{
+			name: "standard VM",
+			sku:  "Standard_D4s_v3",
+		},
+		{
+			name: "SGX VM",
+			sku:  "Standard_DC4s_v3",
+		},
+		{
+			name:                 "DCasv5 confidential VM",
+			sku:                  "Standard_DC4as_v5",
+			expectedSecurityType: "ConfidentialVM",
+		},
+		{
+			name:                 "ECadsv5 confidential VM",
+			sku:                  "Standard_EC8ads_v5",
+			expectedSecurityType: "ConfidentialVM",
+		},
+		{
+			name:                 "trusted launch",
+			sku:                  "Standard_D4s_v3",
+			securityType:         compute.SecurityTypesTrustedLaunch,
+			expectedSecurityType: "TrustedLaunch",
+		},
+		{
+			name:                 "explicitly set on the tags",
+			sku:                  "Standard_DC4as_v5",
+			tags:                 map[string]*string{fmt.Sprintf("%s%s", nodeLabelTagName, "kubernetes.azure.com_security-type"): to.StringPtr("Custom")},
+			expectedSecurityType: "Custom",
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			template := newTestTemplate(false, tc.tags)
+			template.Sku.Name = to.StringPtr(tc.sku)
+			if tc.securityType != "" {
+				template.VirtualMachineProfile.SecurityProfile = &compute.SecurityProfile{SecurityType: tc.securityType}
+			}
+			node, err := buildNodeFromTemplate("security", template, manager)
+			assert.NoError(t, err)
+			securityType, found := node.Labels[securityTypeLabelKey]
+			assert.Equal(t, tc.expectedSecurityType != "", found)
+			assert.Equal(t, tc.expectedSecurityType, securityType)
+		})
+	}
+}
+
+func TestBuildNodeFromTemplateHugePages(t *testing.T) {
+	stubStaticInstanceType(t, &InstanceType{VCPU: 4, MemoryMb: 16384})
+	manager := &AzureManager{config: &Config{}}
+
+	tags := map[string]*string{
+		fmt.Sprintf("%s%s", nodeResourcesTagName, "hugepages-1Gi"): to.StringPtr("2Gi"),
+		fmt.Sprintf("%s%s", nodeResourcesTagName, "hugepages-2Mi"): to.StringPtr("512Mi"),
+	}
+	for _, windows := range []bool{false, true} {
+		node, err := buildNodeFromTemplate("hugepages", newTestTemplate(windows, tags), manager)
+		assert.NoError(t, err)
+		for _, resourceList := range []apiv1.ResourceList{node.Status.Capacity, node.Status.Allocatable} {
+			hugePages1Gi := resourceList[apiv1.ResourceName("hugepages-1Gi")]
+			assert.Equal(t, int64(2*1024*1024*1024), hugePages1Gi.Value())
+			hugePages2Mi := resourceList[apiv1.ResourceName("hugepages-2Mi")]
+			assert.Equal(t, int64(512*1024*1024), hugePages2Mi.Value())
+		}
+	}
+}
+
+func TestBuildNodeFromTemplateAnnotations(t *testing.T) {
+	stubStaticInstanceType(t, &InstanceType{VCPU: 4, MemoryMb: 16384})
+	manager := &AzureManager{config: &Config{}}
+
+	tags := map[string]*string{
+		fmt.Sprintf("%s%s", nodeAnnotationTagName, "example.com_owner"):                                     to.StringPtr("team-a"),
+		fmt.Sprintf("%s%s", nodeAnnotationTagName, "cluster-autoscaler.kubernetes.io_pods-capacity-source"): to.StringPtr("overridden"),
+	}
+	node, err := buildNodeFromTemplate("annotations", newTestTemplate(false, tags), manager)
+	assert.NoError(t, err)
+	assert.Equal(t, "team-a", node.Annotations["example.com/owner"])
+	assert.Equal(t, podsCapacitySourceFallback, node.Annotations[podsCapacitySourceAnnotation])
+}
+
+func TestBuildNodeFromTemplateGPU(t *testing.T) {
+	stubStaticInstanceType(t, &InstanceType{VCPU: 4, MemoryMb: 16384, GPU: 1})
+
+	testCases := []struct {
+		name             string
+		sku              string
+		expectedVendor   string
+		expectedProduct  string
+		fpgaResourceName string
+		expectedResource apiv1.ResourceName
+		missingResources []apiv1.ResourceName
+	}{
+		{
+			name:             "nvidia sku",
+			sku:              "Standard_NC6s_v3",
+			expectedVendor:   gpuVendorNvidia,
+			expectedProduct:  "Tesla-V100-PCIE-16GB",
+			expectedResource: gpu.ResourceNvidiaGPU,
+			missingResources: []apiv1.ResourceName{resourceAMDGPU},
+		},
+		{
+			name:             "nvidia t4 sku",
+			sku:              "Standard_NC8as_T4_v3",
+			expectedVendor:   gpuVendorNvidia,
+			expectedProduct:  "Tesla-T4",
+			expectedResource: gpu.ResourceNvidiaGPU,
+		},
+		{
+			name:             "nvidia a100 promo sku",
+			sku:              "Standard_NC24ads_A100_v4_Promo",
+			expectedVendor:   gpuVendorNvidia,
+			expectedProduct:  "NVIDIA-A100-80GB-PCIe",
+			expectedResource: gpu.ResourceNvidiaGPU,
+		},
+		{
+			name:             "np-series fpga sku",
+			sku:              "Standard_NP10s",
+			expectedVendor:   gpuVendorNone,
+			expectedResource: defaultFPGAResourceName,
+			missingResources: []apiv1.ResourceName{gpu.ResourceNvidiaGPU, resourceAMDGPU},
+		},
+		{
+			name:             "np-series sku with custom fpga resource",
+			sku:              "Standard_NP20s",
+			fpgaResourceName: "xilinx.com/fpga-xilinx_u250_gen3x16_xdma_shell_2_1-0",
+			expectedVendor:   gpuVendorNone,
+			expectedResource: "xilinx.com/fpga-xilinx_u250_gen3x16_xdma_shell_2_1-0",
+			missingResources: []apiv1.ResourceName{gpu.ResourceNvidiaGPU, resourceAMDGPU, defaultFPGAResourceName},
+		},
+		{
+			name:             "amd sku",
+			sku:              "Standard_NV4as_v4",
+			expectedVendor:   gpuVendorAMD,
+			expectedResource: resourceAMDGPU,
+			missingResources: []apiv1.ResourceName{gpu.ResourceNvidiaGPU},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			assert.Equal(t, tc.expectedVendor, getGpuVendorFromSku(tc.sku))
+
+			template := newTestTemplate(false, nil)
+			template.Sku.Name = to.StringPtr(tc.sku)
+			manager := &AzureManager{config: &Config{FPGAResourceName: tc.fpgaResourceName}}
+			node, err := buildNodeFromTemplate("gpu", template, manager)
+			assert.NoError(t, err)
+			if tc.expectedVendor == gpuVendorNone {
+				assert.NotContains(t, node.Labels, GPULabel)
+			} else {
+				assert.Equal(t, tc.expectedVendor, node.Labels[GPULabel])
+			}
+			if tc.expectedResource != "" {
+				assert.Equal(t, int64(1), node.Status.Capacity.Name(tc.expectedResource, resource.DecimalSI).Value())
+			}
+			if tc.expectedProduct == "" {
+				assert.NotContains(t, node.Labels, nvidiaGpuProductLabel)
+			} else {
+				assert.Equal(t, tc.expectedProduct, node.Labels[nvidiaGpuProductLabel])
+			}
+			for _, r := range tc.missingResources {
+				assert.NotContains(t, node.Status.Capacity, r)
+			}
+		})
+	}
+}
+
+func TestCompareInstanceTypes(t *testing.T) {
+	static := InstanceType{VCPU: 4, MemoryMb: 16384, GPU: 0}
+
+	testCases := []struct {
+		name           string
+		dynamic        InstanceType
+		expectedDeltas []instanceTypeDelta
+	}{
+		{
+			name:    "sources agree",
+			dynamic: InstanceType{VCPU: 4, MemoryMb: 16384, GPU: 0},
+		},
+		{
+			name:           "vcpu disagrees",
+			dynamic:        InstanceType{VCPU: 8, MemoryMb: 16384, GPU: 0},
+			expectedDeltas: []instanceTypeDelta{{field: "vcpu", dynamic: 8, static: 4}},
+		},
+		{
+			name:    "all fields disagree",
+			dynamic: InstanceType{VCPU: 2, MemoryMb: 8192, GPU: 1},
+			expectedDeltas: []instanceTypeDelta{
+				{field: "vcpu", dynamic: 2, static: 4},
+				{field: "memoryMb", dynamic: 8192, static: 16384},
+				{field: "gpu", dynamic: 1, static: 0},
+			},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			assert.Equal(t, tc.expectedDeltas, compareInstanceTypes(tc.dynamic, static))
+		})
+	}
+}
+
+func TestBuildNodeFromTemplateDynamicSkuAuthoritative(t *testing.T) {
+	stubStaticInstanceType(t, &InstanceType{VCPU: 4, MemoryMb: 16384})
+	dynamicFunc := GetVMSSTypeDynamically
+	defer func() { GetVMSSTypeDynamically = dynamicFunc }()
+	GetVMSSTypeDynamically = func(template compute.VirtualMachineScaleSet, azCache *azureCache) (InstanceType, error) {
+		return InstanceType{VCPU: 8, MemoryMb: 32768}, nil
+	}
+	manager := newTestAzureManager(t)
+	manager.config.EnableDynamicInstanceList = true
+	template := newTestTemplate(false, nil)
+
+	node, err := buildNodeFromTemplate("dynamic", template, manager)
+	assert.NoError(t, err)
+	assert.Equal(t, int64(8), node.Status.Capacity.Cpu().Value())
+	assert.Equal(t, int64(32768*1024*1024), node.Status.Capacity.Memory().Value())
+
+	// The disagreements are reported once, no matter how often the template is built.
+	expectedReported := map[skuDisagreement]bool{
+		{sku: *template.Sku.Name, field: "vcpu"}:     true,
+		{sku: *template.Sku.Name, field: "memoryMb"}: true,
+	}
+	assert.Equal(t, expectedReported, manager.azureCache.reportedSkuDisagreements)
+	_, err = buildNodeFromTemplate("dynamic", template, manager)
+	assert.NoError(t, err)
+	assert.Equal(t, expectedReported, manager.azureCache.reportedSkuDisagreements)
+	assert.True(t, manager.azureCache.markSkuDisagreementReported(*template.Sku.Name, "vcpu"))
+	assert.False(t, manager.azureCache.markSkuDisagreementReported(*template.Sku.Name, "gpu"))
+}
+
+func TestBuildEphemeralStorage(t *testing.T) {
+	stubStaticInstanceType(t, &InstanceType{VCPU: 4, MemoryMb: 16384})
+	manager := newTestAzureManager(t)
+	manager.config.EnableDynamicInstanceList = true
+	manager.azureCache.azClient.skuClient = &fakeSkuClient{
+		skus: []skucompute.ResourceSku{
+			{
+				Name:         to.StringPtr("Standard_D4s_v3"),
+				ResourceType: to.StringPtr("virtualMachines"),
+				Locations:    &[]string{"eastus"},
+				Capabilities: &[]skucompute.ResourceSkuCapabilities{
+					{Name: to.StringPtr("vCPUs"), Value: to.StringPtr("4")},
+					{Name: to.StringPtr("MemoryGB"), Value: to.StringPtr("16")},
+					{Name: to.StringPtr("MaxResourceVolumeMB"), Value: to.StringPtr("32768")},
+					{Name: to.StringPtr("CachedDiskBytes"), Value: to.StringPtr("107374182400")},
+				},
+			},
+		},
+	}
+	gib := int64(1024 * 1024 * 1024)
+
+	testCases := []struct {
+		name            string
+		tags            map[string]*string
+		storageProfile  *compute.VirtualMachineScaleSetStorageProfile
+		expectedStorage int64
+		expectedFound   bool
+	}{
+		{
+			name:          "no storage profile",
+			expectedFound: false,
+		},
+		{
+			name: "managed os disk",
+			storageProfile: &compute.VirtualMachineScaleSetStorageProfile{
+				OsDisk: &compute.VirtualMachineScaleSetOSDisk{DiskSizeGB: to.Int32Ptr(128)},
+			},
+			expectedStorage: 128 * gib,
+			expectedFound:   true,
+		},
+		{
+			name: "ephemeral os disk on the cache disk",
+			storageProfile: &compute.VirtualMachineScaleSetStorageProfile{
+				OsDisk: &compute.VirtualMachineScaleSetOSDisk{
+					DiffDiskSettings: &compute.DiffDiskSettings{Option: compute.Local, Placement: compute.CacheDisk},
+				},
+			},
+			expectedStorage: 100 * gib,
+			expectedFound:   true,
+		},
+		{
+			name: "ephemeral os disk on the temp disk",
+			storageProfile: &compute.VirtualMachineScaleSetStorageProfile{
+				OsDisk: &compute.VirtualMachineScaleSetOSDisk{
+					DiffDiskSettings: &compute.DiffDiskSettings{Option: compute.Local, Placement: compute.ResourceDisk},
+				},
+			},
+			expectedStorage: 32 * gib,
+			expectedFound:   true,
+		},
+		{
+			name: "temp disk",
+			tags: map[string]*string{nodeEphemeralStorageSourceTagName: to.StringPtr("temp")},
+			storageProfile: &compute.VirtualMachineScaleSetStorageProfile{
+				OsDisk: &compute.VirtualMachineScaleSetOSDisk{DiskSizeGB: to.Int32Ptr(128)},
+			},
+			expectedStorage: 32 * gib,
+			expectedFound:   true,
+		},
+		{
+			name: "first data disk",
+			tags: map[string]*string{nodeEphemeralStorageSourceTagName: to.StringPtr("Data")},
+			storageProfile: &compute.VirtualMachineScaleSetStorageProfile{
+				OsDisk: &compute.VirtualMachineScaleSetOSDisk{DiskSizeGB: to.Int32Ptr(128)},
+				DataDisks: &[]compute.VirtualMachineScaleSetDataDisk{
+					{Lun: to.Int32Ptr(1), DiskSizeGB: to.Int32Ptr(256)},
+					{Lun: to.Int32Ptr(0), DiskSizeGB: to.Int32Ptr(512)},
+				},
+			},
+			expectedStorage: 512 * gib,
+			expectedFound:   true,
+		},
+		{
+			name:          "unknown source",
+			tags:          map[string]*string{nodeEphemeralStorageSourceTagName: to.StringPtr("nfs")},
+			expectedFound: false,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			template := newTestTemplate(false, tc.tags)
+			template.VirtualMachineProfile.StorageProfile = tc.storageProfile
+			storage, found := buildEphemeralStorage(template, manager)
+			assert.Equal(t, tc.expectedFound, found)
+			assert.Equal(t, tc.expectedStorage, storage)
+
+			node, err := buildNodeFromTemplate("ephemeral", template, manager)
+			assert.NoError(t, err)
+			if tc.expectedFound {
+				assert.Equal(t, tc.expectedStorage, node.Status.Capacity.StorageEphemeral().Value())
+			} else {
+				assert.NotContains(t, node.Status.Capacity, apiv1.ResourceEphemeralStorage)
+			}
+		})
+	}
+}
+
+func TestBuildConditions(t *testing.T) {
+	conditionStatuses := func(conditions []apiv1.NodeCondition) map[apiv1.NodeConditionType]apiv1.ConditionStatus {
+		statuses := make(map[apiv1.NodeConditionType]apiv1.ConditionStatus)
+		for _, condition := range conditions {
+			statuses[condition.Type] = condition.Status
+		}
+		return statuses
+	}
+
+	defaultConditions := conditionStatuses(buildConditions(nil))
+	assert.Equal(t, map[apiv1.NodeConditionType]apiv1.ConditionStatus{
+		apiv1.NodeReady:              apiv1.ConditionTrue,
+		apiv1.NodeNetworkUnavailable: apiv1.ConditionFalse,
+		apiv1.NodeDiskPressure:       apiv1.ConditionFalse,
+		apiv1.NodeMemoryPressure:     apiv1.ConditionFalse,
+	}, defaultConditions)
+
+	tags := map[string]*string{
+		fmt.Sprintf("%s%s", nodeConditionTagName, "Ready"):         to.StringPtr("False"),
+		fmt.Sprintf("%s%s", nodeConditionTagName, "PIDPressure"):   to.StringPtr("Unknown"),
+		fmt.Sprintf("%s%s", nodeConditionTagName, "DiskPressure"):  to.StringPtr("maybe"),
+		fmt.Sprintf("%s%s", nodeLabelTagName, "MemoryPressure"):    to.StringPtr("True"),
+		fmt.Sprintf("%s%s", nodeConditionTagName, "CustomProblem"): to.StringPtr("True"),
+	}
+	conditions := buildConditions(tags)
+	assert.Len(t, conditions, 6)
+	assert.Equal(t, map[apiv1.NodeConditionType]apiv1.ConditionStatus{
+		apiv1.NodeReady:              apiv1.ConditionFalse,
+		apiv1.NodeNetworkUnavailable: apiv1.ConditionFalse,
+		apiv1.NodeDiskPressure:       apiv1.ConditionFalse,
+		apiv1.NodeMemoryPressure:     apiv1.ConditionFalse,
+		apiv1.NodePIDPressure:        apiv1.ConditionUnknown,
+		"CustomProblem":              apiv1.ConditionTrue,
+	}, conditionStatuses(conditions))
+
+	t.Run("conditions on template node", func(t *testing.T) {
+		stubStaticInstanceType(t, &InstanceType{VCPU: 4, MemoryMb: 16384})
+		node, err := buildNodeFromTemplate("not-ready", newTestTemplate(false, tags), &AzureManager{config: &Config{}})
+		assert.NoError(t, err)
+		assert.Equal(t, apiv1.ConditionFalse, conditionStatuses(node.Status.Conditions)[apiv1.NodeReady])
+		assert.Equal(t, apiv1.ConditionTrue, conditionStatuses(node.Status.Conditions)["CustomProblem"])
+	})
+}
+
+// stubStaticInstanceType makes GetVMSSTypeStatically return the given instance type until the end of the test.
+func stubStaticInstanceType(t *testing.T, instanceType *InstanceType) {
+	staticFunc := GetVMSSTypeStatically
+	t.Cleanup(func() { GetVMSSTypeStatically = staticFunc })
+	GetVMSSTypeStatically = func(template compute.VirtualMachineScaleSet) (*InstanceType, error) {
+		return instanceType, nil
+	}
+}
+
+func newTestTemplate(windows bool, tags map[string]*string) compute.VirtualMachineScaleSet {
+	template := compute.VirtualMachineScaleSet{
+		Location: to.StringPtr("eastus"),
+		Sku:      &compute.Sku{Name: to.StringPtr("Standard_D4s_v3")},
+		Tags:     tags,
+		VirtualMachineScaleSetProperties: &compute.VirtualMachineScaleSetProperties{
+			VirtualMachineProfile: &compute.VirtualMachineScaleSetVMProfile{
+				OsProfile: &compute.VirtualMachineScaleSetOSProfile{},
+			},
+		},
+	}
+	if windows {
+		template.VirtualMachineProfile.OsProfile.WindowsConfiguration = &compute.WindowsConfiguration{}
+	}
+	return template
+}
+
 func makeTaintSet(taints []apiv1.Taint) map[apiv1.Taint]bool {
 	set := make(map[apiv1.Taint]bool)
 	for _, taint := range taints {
@@ -126,3 +1127,27 @@ func makeTaintSet(taints []apiv1.Taint) map[apiv1.Taint]bool {
 	}
 	return set
 }
+
+func TestGetIntOption(t *testing.T) {
+	options := map[string]string{"valid": "3", "malformed": "three"}
+
+	opt, ok := getIntOption(options, "test-asg", "valid")
+	assert.True(t, ok)
+	assert.Equal(t, 3, opt)
+	_, ok = getIntOption(options, "test-asg", "missing")
+	assert.False(t, ok)
+	_, ok = getIntOption(options, "test-asg", "malformed")
+	assert.False(t, ok)
+}
+
+func TestGetBoolOption(t *testing.T) {
+	options := map[string]string{"valid": "true", "malformed": "yes please"}
+
+	opt, ok := getBoolOption(options, "test-asg", "Valid")
+	assert.True(t, ok)
+	assert.True(t, opt)
+	_, ok = getBoolOption(options, "test-asg", "missing")
+	assert.False(t, ok)
+	_, ok = getBoolOption(options, "test-asg", "malformed")
+	assert.False(t, ok)
+}
@@ -78,10 +78,30 @@ const (
 	k8sWindowsVMAgentOrchestratorNameIndex = 2
 	k8sWindowsVMAgentPoolInfoIndex         = 3
 
-	nodeLabelTagName     = "k8s.io_cluster-autoscaler_node-template_label_"
-	nodeTaintTagName     = "k8s.io_cluster-autoscaler_node-template_taint_"
-	nodeResourcesTagName = "k8s.io_cluster-autoscaler_node-template_resources_"
-	nodeOptionsTagName   = "k8s.io_cluster-autoscaler_node-template_autoscaling-options_"
+	// nodeTemplateTagPrefix is the prefix of the tags describing template nodes, which aren't node labels themselves
+	nodeTemplateTagPrefix = "k8s.io_cluster-autoscaler_node-template_"
+	nodeLabelTagName      = "k8s.io_cluster-autoscaler_node-template_label_"
+	nodeTaintTagName      = "k8s.io_cluster-autoscaler_node-template_taint_"
+	nodeResourcesTagName  = "k8s.io_cluster-autoscaler_node-template_resources_"
+	nodeOptionsTagName    = "k8s.io_cluster-autoscaler_node-template_autoscaling-options_"
+	// instancesRefreshPeriodOptionKey is the autoscaling option overriding vmssVmsCacheTTL for a scale set, e.g. <nodeOptionsTagName>instancesrefreshperiod=5m
+	instancesRefreshPeriodOptionKey = "instancesrefreshperiod"
+	// nodeAnnotationTagName sets annotations on the simulated nodes, e.g. <prefix>example.com_foo=bar
+	nodeAnnotationTagName = "k8s.io_cluster-autoscaler_node-template_annotation_"
+	// nodeWindowsReservedTagName overrides the resources reserved on Windows nodes, e.g. <prefix>cpu=500m
+	nodeWindowsReservedTagName = "k8s.io_cluster-autoscaler_node-template_windows-reserved_"
+	// nodeKubeReservedTagName and nodeSystemReservedTagName set the kubelet's kube-reserved and system-reserved
+	// resources, e.g. <prefix>memory=1Gi; when set they replace the default reservations of the template node
+	nodeKubeReservedTagName   = "k8s.io_cluster-autoscaler_node-template_kube-reserved_"
+	nodeSystemReservedTagName = "k8s.io_cluster-autoscaler_node-template_system-reserved_"
+	// nodeConditionTagName overrides the simulated node conditions, e.g. <prefix>Ready=False
+	nodeConditionTagName = "k8s.io_cluster-autoscaler_node-template_condition_"
+	// scaleFromZeroDisabledTagName set to "true" keeps existing nodes of the scale set but prevents scaling it up from zero
+	scaleFromZeroDisabledTagName = "k8s.io_cluster-autoscaler_scale-from-zero-disabled"
+	// nodeNetworkPluginTagName gives the network plugin of the nodes, selecting their default max pods: kubenet, azure (Azure CNI) or azure-overlay
+	nodeNetworkPluginTagName = "k8s.io_cluster-autoscaler_node-template_network-plugin"
+	// nodeEphemeralStorageSourceTagName selects the disk backing the ephemeral storage of nodes: os (default), temp or data
+	nodeEphemeralStorageSourceTagName = "k8s.io_cluster-autoscaler_node-template_ephemeral-storage-source"
 
 	// PowerStates reflect the operational state of a VM
 	// From https://learn.microsoft.com/en-us/java/api/com.microsoft.azure.management.compute.powerstate?view=azure-java-stable
@@ -623,6 +643,11 @@ func isRunningVmPowerState(powerState string) bool {
 	return powerState == vmPowerStateRunning || powerState == vmPowerStateStarting
 }
 
+// isNotStartedVmPowerState returns true if the power state of a VM means it hasn't started running yet.
+func isNotStartedVmPowerState(powerState string) bool {
+	return powerState == "" || powerState == vmPowerStateUnknown || powerState == vmPowerStateStarting
+}
+
 func isKnownVmPowerState(powerState string) bool {
 	knownPowerStates := map[string]bool{
 		vmPowerStateStarting:     true,
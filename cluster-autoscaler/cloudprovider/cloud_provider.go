@@ -236,6 +236,15 @@ type NodeGroup interface {
 	GetOptions(defaults config.NodeGroupAutoscalingOptions) (*config.NodeGroupAutoscalingOptions, error)
 }
 
+// ScaleDownDeferrer can optionally be implemented by node groups which need scale-down to be
+// temporarily deferred, for example while the cloud provider is upgrading their instances.
+// Scale-up of such node groups is not affected.
+type ScaleDownDeferrer interface {
+	// ScaleDownDeferred returns true and a human readable reason if scale-down of
+	// the node group should be deferred.
+	ScaleDownDeferred() (bool, string)
+}
+
 // Instance represents a cloud-provider node. The node does not necessarily map to k8s node
 // i.e it does not have to be registered in k8s cluster despite being returned by NodeGroup.Nodes()
 // method. Also it is sane to have Instance object for nodes which are being created or deleted.
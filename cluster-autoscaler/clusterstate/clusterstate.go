@@ -86,6 +86,9 @@ type ClusterStateRegistryConfig struct {
 	// Minimum number of nodes that must be unready for MaxTotalUnreadyPercentage to apply.
 	// This is to ensure that in very small clusters (e.g. 2 nodes) a single node's failure doesn't disable autoscaling.
 	OkTotalUnreadyCount int
+	// ResetBackoffOnInstanceRegistration resets the backoff of a node group as soon as one of its instances
+	// transitions from creating to running, instead of waiting for the backoff to expire.
+	ResetBackoffOnInstanceRegistration bool
 }
 
 // IncorrectNodeGroupSize contains information about how much the current size of the node group
@@ -365,6 +368,9 @@ func (csr *ClusterStateRegistry) UpdateNodes(nodes []*apiv1.Node, nodeInfosForGr
 	// updateScaleRequests relies on acceptableRanges being up to date
 	csr.updateAcceptableRanges(targetSizes)
 	csr.updateScaleRequests(currentTime)
+	if csr.config.ResetBackoffOnInstanceRegistration {
+		csr.resetBackoffOnInstanceRegistration(currentTime)
+	}
 	csr.handleInstanceCreationErrors(currentTime)
 	//  recalculate acceptable ranges after removing timed out requests
 	csr.updateAcceptableRanges(targetSizes)
@@ -1070,6 +1076,34 @@ func (csr *ClusterStateRegistry) GetAutoscaledNodesCount() (currentSize, targetS
 	return currentSize, targetSize
 }
 
+// resetBackoffOnInstanceRegistration removes the backoff of node groups in which an instance
+// finished creating since the last update, as the node group is able to provision nodes again.
+// To be executed under a lock.
+func (csr *ClusterStateRegistry) resetBackoffOnInstanceRegistration(currentTime time.Time) {
+	for _, nodeGroup := range csr.cloudProvider.NodeGroups() {
+		nodeInfo := csr.nodeInfosForGroups[nodeGroup.Id()]
+		if !csr.backoff.BackoffStatus(nodeGroup, nodeInfo, currentTime).IsBackedOff {
+			continue
+		}
+		previousStates := make(map[string]cloudprovider.InstanceState)
+		for _, instance := range csr.previousCloudProviderNodeInstances[nodeGroup.Id()] {
+			if instance.Status != nil {
+				previousStates[instance.Id] = instance.Status.State
+			}
+		}
+		for _, instance := range csr.cloudProviderNodeInstances[nodeGroup.Id()] {
+			if instance.Status == nil || instance.Status.State != cloudprovider.InstanceRunning {
+				continue
+			}
+			if previousState, found := previousStates[instance.Id]; found && previousState == cloudprovider.InstanceCreating {
+				klog.V(2).Infof("Instance %s of node group %s finished creating, removing node group backoff", instance.Id, nodeGroup.Id())
+				csr.backoff.RemoveBackoff(nodeGroup, nodeInfo)
+				break
+			}
+		}
+	}
+}
+
 func (csr *ClusterStateRegistry) handleInstanceCreationErrors(currentTime time.Time) {
 	nodeGroups := csr.cloudProvider.NodeGroups()
 
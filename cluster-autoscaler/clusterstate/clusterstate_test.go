@@ -867,6 +867,69 @@ func TestScaleUpBackoff(t *testing.T) {
 	assert.Equal(t, backoff.Status{IsBackedOff: false}, clusterstate.backoff.BackoffStatus(ng1, nil, now))
 }
 
+func TestResetBackoffOnInstanceRegistration(t *testing.T) {
+	for _, tc := range []struct {
+		name                string
+		resetOnRegistration bool
+		previousState       cloudprovider.InstanceState
+		wantBackedOff       bool
+	}{
+		{
+			name:                "instance finished creating resets backoff",
+			resetOnRegistration: true,
+			previousState:       cloudprovider.InstanceCreating,
+		},
+		{
+			name:                "instance already running doesn't reset backoff",
+			resetOnRegistration: true,
+			previousState:       cloudprovider.InstanceRunning,
+			wantBackedOff:       true,
+		},
+		{
+			name:          "backoff isn't reset if disabled",
+			previousState: cloudprovider.InstanceCreating,
+			wantBackedOff: true,
+		},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			now := time.Now()
+			ng1_1 := BuildTestNode("ng1-1", 1000, 1000)
+			SetNodeReadyState(ng1_1, true, now.Add(-time.Minute))
+			ng1_2 := BuildTestNode("ng1-2", 1000, 1000)
+			SetNodeReadyState(ng1_2, true, now.Add(-time.Minute))
+
+			provider := testprovider.NewTestCloudProvider(nil, nil)
+			provider.AddNodeGroup("ng1", 1, 10, 2)
+			ng1 := provider.GetNodeGroup("ng1")
+			provider.AddNode("ng1", ng1_1)
+			provider.AddNode("ng1", ng1_2)
+
+			fakeClient := &fake.Clientset{}
+			fakeLogRecorder, _ := utils.NewStatusMapRecorder(fakeClient, "kube-system", kube_record.NewFakeRecorder(5), false, "my-cool-configmap")
+			clusterstate := NewClusterStateRegistry(provider, ClusterStateRegistryConfig{
+				MaxTotalUnreadyPercentage:          10,
+				OkTotalUnreadyCount:                1,
+				ResetBackoffOnInstanceRegistration: tc.resetOnRegistration,
+			}, fakeLogRecorder, newBackoff(), nodegroupconfig.NewDefaultNodeGroupConfigProcessor(config.NodeGroupAutoscalingOptions{MaxNodeProvisionTime: 15 * time.Minute}))
+
+			clusterstate.backoff.Backoff(ng1, nil, cloudprovider.InstanceErrorInfo{
+				ErrorClass: cloudprovider.OutOfResourcesErrorClass,
+				ErrorCode:  "AllocationFailed",
+			}, now)
+			clusterstate.cloudProviderNodeInstances = map[string][]cloudprovider.Instance{
+				"ng1": {
+					{Id: "ng1-1", Status: &cloudprovider.InstanceStatus{State: cloudprovider.InstanceRunning}},
+					{Id: "ng1-2", Status: &cloudprovider.InstanceStatus{State: tc.previousState}},
+				},
+			}
+
+			err := clusterstate.UpdateNodes([]*apiv1.Node{ng1_1, ng1_2}, nil, now)
+			assert.NoError(t, err)
+			assert.Equal(t, tc.wantBackedOff, clusterstate.backoff.BackoffStatus(ng1, nil, now).IsBackedOff)
+		})
+	}
+}
+
 func TestGetClusterSize(t *testing.T) {
 	now := time.Now()
 
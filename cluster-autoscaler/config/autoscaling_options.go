@@ -53,6 +53,21 @@ type NodeGroupAutoscalingOptions struct {
 	ZeroOrMaxNodeScaling bool
 	// IgnoreDaemonSetsUtilization sets if daemonsets utilization should be considered during node scale-down
 	IgnoreDaemonSetsUtilization bool
+	// MaxDrainParallelism is the maximum number of nodes from this node group that can be drained and deleted in parallel.
+	// It is applied on top of the global MaxDrainParallelism. Zero means no per node group limit.
+	// It doesn't apply to node groups with ZeroOrMaxNodeScaling, which are always drained as a whole.
+	MaxDrainParallelism int
+	// MaxIdleNodes is the maximum number of empty nodes from this node group kept until they're unneeded for
+	// ScaleDownUnneededTime. Empty nodes above it are removed as soon as they're unneeded. Zero means no limit.
+	MaxIdleNodes int
+	// ExcludeTolerateAllPods means that pods tolerating all taints don't trigger scale-up of the node group
+	// if its nodes are tainted. It guards node groups reserved with taints from accidental provisioning.
+	ExcludeTolerateAllPods bool
+	// ExcludeFromBalancing means that the node group is scaled independently and never balanced with similar node groups.
+	ExcludeFromBalancing bool
+	// ScaleDownDisabled means that no node of the node group is considered for scale down, as if they all had
+	// the scale down disabled annotation.
+	ScaleDownDisabled bool
 }
 
 // GCEOptions contain autoscaling options specific to GCE cloud provider.
@@ -249,6 +264,8 @@ type AutoscalingOptions struct {
 	MaxNodeGroupBackoffDuration time.Duration
 	// NodeGroupBackoffResetTimeout is the time after last failed scale-up when the backoff duration is reset.
 	NodeGroupBackoffResetTimeout time.Duration
+	// ResetBackoffOnInstanceRegistration resets the backoff of a NodeGroup as soon as one of its new instances finishes creating.
+	ResetBackoffOnInstanceRegistration bool
 	// MaxScaleDownParallelism is the maximum number of nodes (both empty and needing drain) that can be deleted in parallel.
 	MaxScaleDownParallelism int
 	// MaxDrainParallelism is the maximum number of nodes needing drain, that can be drained and deleted in parallel.
@@ -286,6 +303,14 @@ type AutoscalingOptions struct {
 	BypassedSchedulers map[string]bool
 	// ProvisioningRequestEnabled tells if CA processes ProvisioningRequest.
 	ProvisioningRequestEnabled bool
+	// ProvisioningRequestPodAnnotationKey is the annotation of the pods naming the ProvisioningRequest they consume.
+	ProvisioningRequestPodAnnotationKey string
+	// ProvisioningRequestExternalClasses are the provisioning classes of the ProvisioningRequests handled by an
+	// external provisioner. The pods consuming other ProvisioningRequests trigger normal scale-ups. Empty for all classes.
+	ProvisioningRequestExternalClasses []string
+	// ScaleDownBlockedWarningIterations is the number of consecutive iterations a node group can be kept
+	// above its min size by idle nodes that can't be removed before a warning is emitted. Zero disables the warnings.
+	ScaleDownBlockedWarningIterations int
 }
 
 // KubeClientOptions specify options for kube client
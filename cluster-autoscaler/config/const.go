@@ -40,6 +40,16 @@ const (
 	DefaultMaxNodeProvisionTimeKey = "maxnodeprovisiontime"
 	// DefaultIgnoreDaemonSetsUtilizationKey identifies IgnoreDaemonSetsUtilization autoscaling option
 	DefaultIgnoreDaemonSetsUtilizationKey = "ignoredaemonsetsutilization"
+	// DefaultMaxDrainParallelismKey identifies MaxDrainParallelism autoscaling option
+	DefaultMaxDrainParallelismKey = "maxdrainparallelism"
+	// DefaultMaxIdleNodesKey identifies MaxIdleNodes autoscaling option
+	DefaultMaxIdleNodesKey = "maxidlenodes"
+	// DefaultExcludeTolerateAllPodsKey identifies ExcludeTolerateAllPods autoscaling option
+	DefaultExcludeTolerateAllPodsKey = "excludetolerateallpods"
+	// DefaultExcludeFromBalancingKey identifies ExcludeFromBalancing autoscaling option
+	DefaultExcludeFromBalancingKey = "excludefrombalancing"
+	// DefaultScaleDownDisabledKey identifies ScaleDownDisabled autoscaling option
+	DefaultScaleDownDisabledKey = "scaledowndisabled"
 
 	// DefaultScaleDownUnneededTime is the default time duration for which CA waits before deleting an unneeded node
 	DefaultScaleDownUnneededTime = 10 * time.Minute
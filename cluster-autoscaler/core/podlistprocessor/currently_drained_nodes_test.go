@@ -300,3 +300,7 @@ func (m *mockActuationStatus) DeletionResults() (map[string]status.NodeDeleteRes
 func (m *mockActuationStatus) DeletionsCount(_ string) int {
 	return 0
 }
+
+func (m *mockActuationStatus) DrainsCount(_ string) int {
+	return 0
+}
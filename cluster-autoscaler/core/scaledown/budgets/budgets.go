@@ -54,6 +54,7 @@ func NewScaleDownBudgetProcessor(ctx *context.AutoscalingContext) *ScaleDownBudg
 func (bp *ScaleDownBudgetProcessor) CropNodes(as scaledown.ActuationStatus, empty, drain []*apiv1.Node) (emptyToDelete, drainToDelete []*NodeGroupView) {
 	emptyIndividual, emptyAtomic := bp.categorize(bp.group(empty))
 	drainIndividual, drainAtomic := bp.categorize(bp.group(drain))
+	drainIndividual = bp.cropToNodeGroupDrainBudgets(as, drainIndividual)
 
 	emptyAtomicMap := groupBuckets(emptyAtomic)
 	drainAtomicMap := groupBuckets(drainAtomic)
@@ -147,6 +148,34 @@ func (bp *ScaleDownBudgetProcessor) CropNodes(as scaledown.ActuationStatus, empt
 	return emptyToDelete, drainToDelete
 }
 
+// cropToNodeGroupDrainBudgets crops the provided node group views to respect per node group
+// max drain parallelism, taking drains which are already in progress into account.
+// Node groups without a per node group limit are returned unchanged.
+func (bp *ScaleDownBudgetProcessor) cropToNodeGroupDrainBudgets(as scaledown.ActuationStatus, groups []*NodeGroupView) []*NodeGroupView {
+	result := make([]*NodeGroupView, 0, len(groups))
+	for _, bucket := range groups {
+		autoscalingOptions, err := bucket.Group.GetOptions(bp.ctx.NodeGroupDefaults)
+		if err != nil && err != cloudprovider.ErrNotImplemented {
+			klog.Errorf("Failed to get autoscaling options for node group %s: %v", bucket.Group.Id(), err)
+			continue
+		}
+		if autoscalingOptions == nil || autoscalingOptions.MaxDrainParallelism <= 0 {
+			result = append(result, bucket)
+			continue
+		}
+		budget := autoscalingOptions.MaxDrainParallelism - as.DrainsCount(bucket.Group.Id())
+		if budget < 1 {
+			klog.V(4).Infof("Skipping drain of %d nodes from node group %s: max drain parallelism %d reached", len(bucket.Nodes), bucket.Group.Id(), autoscalingOptions.MaxDrainParallelism)
+			continue
+		}
+		if budget < len(bucket.Nodes) {
+			bucket.Nodes = bucket.Nodes[:budget]
+		}
+		result = append(result, bucket)
+	}
+	return result
+}
+
 func groupBuckets(buckets []*NodeGroupView) map[string]*NodeGroupView {
 	grouped := map[string]*NodeGroupView{}
 	for _, bucket := range buckets {
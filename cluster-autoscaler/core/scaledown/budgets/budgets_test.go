@@ -467,6 +467,84 @@ func TestCropNodesToBudgets(t *testing.T) {
 	}
 }
 
+func TestCropNodesToNodeGroupDrainBudgets(t *testing.T) {
+	limited := drainLimitedNodeGroup("limited", 20, 2)
+	limited2 := drainLimitedNodeGroup("limited-2", 20, 3)
+	unlimited := sizedNodeGroup("unlimited", 20, false)
+	for tn, tc := range map[string]struct {
+		drainsInProgress map[string]int
+		drain            []*NodeGroupView
+		wantDrain        []*NodeGroupView
+	}{
+		"many drain candidates are cropped to node group limit": {
+			drain:     generateNodeGroupViewList(limited, 0, 10),
+			wantDrain: generateNodeGroupViewList(limited, 0, 2),
+		},
+		"drains in progress count against node group limit": {
+			drainsInProgress: map[string]int{"limited": 1},
+			drain:            generateNodeGroupViewList(limited, 0, 10),
+			wantDrain:        generateNodeGroupViewList(limited, 0, 1),
+		},
+		"node group limit reached": {
+			drainsInProgress: map[string]int{"limited": 2},
+			drain:            generateNodeGroupViewList(limited, 0, 10),
+			wantDrain:        []*NodeGroupView{},
+		},
+		"drains in other node groups don't count against node group limit": {
+			drainsInProgress: map[string]int{"unlimited": 2},
+			drain:            generateNodeGroupViewList(limited, 0, 10),
+			wantDrain:        generateNodeGroupViewList(limited, 0, 2),
+		},
+		"node group limits are independent of each other": {
+			drain:     append(generateNodeGroupViewList(limited, 0, 10), generateNodeGroupViewList(limited2, 0, 10)...),
+			wantDrain: append(generateNodeGroupViewList(limited, 0, 2), generateNodeGroupViewList(limited2, 0, 3)...),
+		},
+		"node group without limit is only cropped to global budget": {
+			drain:     append(generateNodeGroupViewList(limited, 0, 10), generateNodeGroupViewList(unlimited, 0, 10)...),
+			wantDrain: append(generateNodeGroupViewList(limited, 0, 2), generateNodeGroupViewList(unlimited, 0, 3)...),
+		},
+	} {
+		t.Run(tn, func(t *testing.T) {
+			provider := testprovider.NewTestCloudProvider(nil, func(nodeGroup string, node string) error {
+				return nil
+			})
+			for _, bucket := range tc.drain {
+				bucket.Group.(*testprovider.TestNodeGroup).SetCloudProvider(provider)
+				provider.InsertNodeGroup(bucket.Group)
+				for _, node := range bucket.Nodes {
+					provider.AddNode(bucket.Group.Id(), node)
+				}
+			}
+
+			ctx := &context.AutoscalingContext{
+				AutoscalingOptions: config.AutoscalingOptions{
+					MaxScaleDownParallelism:     10,
+					MaxDrainParallelism:         5,
+					NodeDeletionBatcherInterval: 0 * time.Second,
+					NodeDeleteDelayAfterTaint:   1 * time.Second,
+				},
+				CloudProvider: provider,
+			}
+			ndt := deletiontracker.NewNodeDeletionTracker(1 * time.Hour)
+			for ng, count := range tc.drainsInProgress {
+				for i := 0; i < count; i++ {
+					ndt.StartDeletionWithDrain(ng, fmt.Sprintf("%s-drain-node-%d", ng, i))
+				}
+			}
+			drainList := []*apiv1.Node{}
+			for _, bucket := range tc.drain {
+				drainList = append(drainList, bucket.Nodes...)
+			}
+
+			budgeter := NewScaleDownBudgetProcessor(ctx)
+			_, gotDrain := budgeter.CropNodes(ndt, []*apiv1.Node{}, drainList)
+			if diff := cmp.Diff(tc.wantDrain, gotDrain, cmpopts.EquateEmpty(), transformNodeGroupView); diff != "" {
+				t.Errorf("cropNodesToBudgets drain nodes diff (-want +got):\n%s", diff)
+			}
+		})
+	}
+}
+
 // transformNodeGroupView transforms a NodeGroupView to a structure that can be directly compared with other node bucket.
 var transformNodeGroupView = cmp.Transformer("transformNodeGroupView", func(b NodeGroupView) interface{} {
 	return struct {
@@ -486,6 +564,14 @@ func sizedNodeGroup(id string, size int, atomic bool) cloudprovider.NodeGroup {
 	return ng
 }
 
+func drainLimitedNodeGroup(id string, size, maxDrainParallelism int) cloudprovider.NodeGroup {
+	ng := testprovider.NewTestNodeGroup(id, 100, 0, size, true, false, "n1-standard-2", nil, nil)
+	ng.SetOptions(&config.NodeGroupAutoscalingOptions{
+		MaxDrainParallelism: maxDrainParallelism,
+	})
+	return ng
+}
+
 func generateNodes(from, to int, prefix string) []*apiv1.Node {
 	var result []*apiv1.Node
 	for i := from; i < to; i++ {
@@ -34,6 +34,9 @@ type NodeDeletionTracker struct {
 	// A map which keeps track of deletions in progress for nodepools.
 	// Key is a node group id and value is a number of node deletions in progress.
 	deletionsPerNodeGroup map[string]int
+	// A map which keeps track of drains in progress for nodepools.
+	// Key is a node group id and value is a number of node drains in progress.
+	drainsPerNodeGroup map[string]int
 	// This mapping contains node names of all empty nodes currently undergoing deletion.
 	emptyNodeDeletions map[string]bool
 	// This mapping contains node names of all nodes currently undergoing drain and deletion.
@@ -57,6 +60,7 @@ type deletionResult struct {
 func NewNodeDeletionTracker(podEvictionsTTL time.Duration) *NodeDeletionTracker {
 	return &NodeDeletionTracker{
 		deletionsPerNodeGroup: make(map[string]int),
+		drainsPerNodeGroup:    make(map[string]int),
 		emptyNodeDeletions:    make(map[string]bool),
 		drainedNodeDeletions:  make(map[string]bool),
 		clock:                 clock.RealClock{},
@@ -79,6 +83,7 @@ func (n *NodeDeletionTracker) StartDeletionWithDrain(nodeGroupId, nodeName strin
 	n.Lock()
 	defer n.Unlock()
 	n.deletionsPerNodeGroup[nodeGroupId]++
+	n.drainsPerNodeGroup[nodeGroupId]++
 	n.drainedNodeDeletions[nodeName] = true
 }
 
@@ -100,6 +105,12 @@ func (n *NodeDeletionTracker) EndDeletion(nodeGroupId, nodeName string, result s
 	if n.deletionsPerNodeGroup[nodeGroupId] <= 0 {
 		delete(n.deletionsPerNodeGroup, nodeGroupId)
 	}
+	if n.drainedNodeDeletions[nodeName] {
+		n.drainsPerNodeGroup[nodeGroupId]--
+		if n.drainsPerNodeGroup[nodeGroupId] <= 0 {
+			delete(n.drainsPerNodeGroup, nodeGroupId)
+		}
+	}
 	delete(n.emptyNodeDeletions, nodeName)
 	delete(n.drainedNodeDeletions, nodeName)
 }
@@ -148,6 +159,13 @@ func (n *NodeDeletionTracker) DeletionsCount(nodeGroupId string) int {
 	return n.deletionsPerNodeGroup[nodeGroupId]
 }
 
+// DrainsCount returns the number of drains in progress for the given node group.
+func (n *NodeDeletionTracker) DrainsCount(nodeGroupId string) int {
+	n.Lock()
+	defer n.Unlock()
+	return n.drainsPerNodeGroup[nodeGroupId]
+}
+
 // DeletionResults returns deletion results in a map form, along with the timestamp of last result.
 func (n *NodeDeletionTracker) DeletionResults() (map[string]status.NodeDeleteResult, time.Time) {
 	n.Lock()
@@ -183,6 +201,9 @@ func (n *NodeDeletionTracker) Snapshot() *NodeDeletionTracker {
 	for k, val := range n.deletionsPerNodeGroup {
 		snapshot.deletionsPerNodeGroup[k] = val
 	}
+	for k, val := range n.drainsPerNodeGroup {
+		snapshot.drainsPerNodeGroup[k] = val
+	}
 	for _, eviction := range n.evictions.ToSlice() {
 		snapshot.evictions.RegisterElement(eviction)
 	}
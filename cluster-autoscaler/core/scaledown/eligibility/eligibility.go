@@ -132,6 +132,11 @@ func (c *Checker) unremovableReasonAndNodeUtilization(context *context.Autoscali
 		return simulator.NotAutoscaled, nil
 	}
 
+	if isNodeGroupScaleDownDisabled(context, nodeGroup) {
+		klog.V(1).Infof("Skipping %s from delete consideration - scale down is disabled for its node group %s", node.Name, nodeGroup.Id())
+		return simulator.NodeGroupScaleDownDisabled, nil
+	}
+
 	ignoreDaemonSetsUtilization, err := c.configGetter.GetIgnoreDaemonSetsUtilization(nodeGroup)
 	if err != nil {
 		klog.Warningf("Couldn't retrieve `IgnoreDaemonSetsUtilization` option for node %v: %v", node.Name, err)
@@ -190,6 +195,15 @@ func (c *Checker) isNodeBelowUtilizationThreshold(context *context.AutoscalingCo
 	return true, nil
 }
 
+// isNodeGroupScaleDownDisabled tells if scale down is disabled by the autoscaling options of the node group.
+func isNodeGroupScaleDownDisabled(context *context.AutoscalingContext, nodeGroup cloudprovider.NodeGroup) bool {
+	autoscalingOptions, err := nodeGroup.GetOptions(context.NodeGroupDefaults)
+	if err != nil && err != cloudprovider.ErrNotImplemented {
+		klog.Errorf("Failed to get autoscaling options for node group %s: %v", nodeGroup.Id(), err)
+	}
+	return autoscalingOptions != nil && autoscalingOptions.ScaleDownDisabled
+}
+
 // HasNoScaleDownAnnotation checks whether the node has an annotation blocking it from being scaled down.
 func HasNoScaleDownAnnotation(node *apiv1.Node) bool {
 	return node.Annotations[ScaleDownDisabledKey] == "true"
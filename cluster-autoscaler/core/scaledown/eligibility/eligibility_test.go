@@ -26,6 +26,7 @@ import (
 	"k8s.io/autoscaler/cluster-autoscaler/core/scaledown/unremovable"
 	. "k8s.io/autoscaler/cluster-autoscaler/core/test"
 	"k8s.io/autoscaler/cluster-autoscaler/processors/nodegroupconfig"
+	"k8s.io/autoscaler/cluster-autoscaler/simulator"
 	"k8s.io/autoscaler/cluster-autoscaler/simulator/clustersnapshot"
 	"k8s.io/autoscaler/cluster-autoscaler/utils/taints"
 	. "k8s.io/autoscaler/cluster-autoscaler/utils/test"
@@ -183,3 +184,39 @@ func TestFilterOutUnremovable(t *testing.T) {
 		})
 	}
 }
+
+func TestFilterOutUnremovableNodeGroupScaleDownDisabled(t *testing.T) {
+	now := time.Now()
+	options := config.AutoscalingOptions{
+		UnremovableNodeRecheckTimeout: 5 * time.Minute,
+		NodeGroupDefaults: config.NodeGroupAutoscalingOptions{
+			ScaleDownUtilizationThreshold:    config.DefaultScaleDownUtilizationThreshold,
+			ScaleDownGpuUtilizationThreshold: config.DefaultScaleDownGpuUtilizationThreshold,
+		},
+	}
+	protectedOptions := options.NodeGroupDefaults
+	protectedOptions.ScaleDownDisabled = true
+
+	protectedNode := BuildTestNode("protected", 1000, 10)
+	SetNodeReadyState(protectedNode, true, time.Time{})
+	regularNode := BuildTestNode("regular", 1000, 10)
+	SetNodeReadyState(regularNode, true, time.Time{})
+	nodes := []*apiv1.Node{protectedNode, regularNode}
+
+	provider := testprovider.NewTestCloudProvider(nil, nil)
+	provider.AddNodeGroupWithCustomOptions("protected-ng", 1, 10, 1, &protectedOptions)
+	provider.AddNodeGroup("regular-ng", 1, 10, 1)
+	provider.AddNode("protected-ng", protectedNode)
+	provider.AddNode("regular-ng", regularNode)
+
+	context, err := NewScaleTestAutoscalingContext(options, &fake.Clientset{}, nil, provider, nil, nil)
+	if err != nil {
+		t.Fatalf("Could not create autoscaling context: %v", err)
+	}
+	clustersnapshot.InitializeClusterSnapshotOrDie(t, context.ClusterSnapshot, nodes, nil)
+	c := NewChecker(nodegroupconfig.NewDefaultNodeGroupConfigProcessor(options.NodeGroupDefaults))
+	got, _, unremovableNodes := c.FilterOutUnremovable(&context, nodes, now, unremovable.NewNodes())
+	assert.Equal(t, []string{"regular"}, got)
+	assert.Len(t, unremovableNodes, 1)
+	assert.Equal(t, simulator.NodeGroupScaleDownDisabled, unremovableNodes[0].Reason)
+}
@@ -877,6 +877,10 @@ func (f *fakeActuationStatus) DeletionsCount(nodeGroup string) int {
 	return 0
 }
 
+func (f *fakeActuationStatus) DrainsCount(nodeGroup string) int {
+	return 0
+}
+
 type fakeEligibilityChecker struct {
 	eligible map[string]bool
 }
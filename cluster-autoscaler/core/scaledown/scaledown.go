@@ -74,6 +74,9 @@ type ActuationStatus interface {
 	// DeletionsCount returns total number of ongoing deletions in a given
 	// node group.
 	DeletionsCount(nodeGroupId string) int
+	// DrainsCount returns number of ongoing deletions of drained nodes in
+	// a given node group.
+	DrainsCount(nodeGroupId string) int
 	// RecentEvictions returns a list of pods that were recently removed by
 	// the Actuator and hence are likely to get recreated elsewhere in the
 	// cluster.
@@ -18,6 +18,7 @@ package unneeded
 
 import (
 	"reflect"
+	"sort"
 	"time"
 
 	"k8s.io/autoscaler/cluster-autoscaler/cloudprovider"
@@ -121,10 +122,11 @@ func (n *Nodes) RemovableAt(context *context.AutoscalingContext, ts time.Time, r
 	nodeGroupSize := utils.GetNodeGroupSizeMap(context.CloudProvider)
 	resourcesLeftCopy := resourcesLeft.DeepCopy()
 	emptyNodes, drainNodes := n.splitEmptyAndNonEmptyNodes()
+	overIdleLimit := nodesOverIdleLimit(context, emptyNodes)
 
 	for nodeName, v := range emptyNodes {
 		klog.V(2).Infof("%s was unneeded for %s", nodeName, ts.Sub(v.since).String())
-		if r := n.unremovableReason(context, v, ts, nodeGroupSize, resourcesLeftCopy, resourcesWithLimits, as); r != simulator.NoReason {
+		if r := n.unremovableReason(context, v, ts, overIdleLimit[nodeName], nodeGroupSize, resourcesLeftCopy, resourcesWithLimits, as); r != simulator.NoReason {
 			unremovable = append(unremovable, &simulator.UnremovableNode{Node: v.ntbr.Node, Reason: r})
 			continue
 		}
@@ -132,7 +134,7 @@ func (n *Nodes) RemovableAt(context *context.AutoscalingContext, ts time.Time, r
 	}
 	for nodeName, v := range drainNodes {
 		klog.V(2).Infof("%s was unneeded for %s", nodeName, ts.Sub(v.since).String())
-		if r := n.unremovableReason(context, v, ts, nodeGroupSize, resourcesLeftCopy, resourcesWithLimits, as); r != simulator.NoReason {
+		if r := n.unremovableReason(context, v, ts, false, nodeGroupSize, resourcesLeftCopy, resourcesWithLimits, as); r != simulator.NoReason {
 			unremovable = append(unremovable, &simulator.UnremovableNode{Node: v.ntbr.Node, Reason: r})
 			continue
 		}
@@ -141,7 +143,54 @@ func (n *Nodes) RemovableAt(context *context.AutoscalingContext, ts time.Time, r
 	return
 }
 
-func (n *Nodes) unremovableReason(context *context.AutoscalingContext, v *node, ts time.Time, nodeGroupSize map[string]int, resourcesLeft resource.Limits, resourcesWithLimits []string, as scaledown.ActuationStatus) simulator.UnremovableReason {
+// nodesOverIdleLimit returns the names of empty ready nodes exceeding the max idle nodes
+// of their node group. Nodes which were unneeded the longest are returned first.
+func nodesOverIdleLimit(context *context.AutoscalingContext, emptyNodes map[string]*node) map[string]bool {
+	idleNodes := make(map[string][]*node)
+	maxIdleNodes := make(map[string]int)
+	for _, v := range emptyNodes {
+		if ready, _, _ := kube_util.GetReadinessState(v.ntbr.Node); !ready {
+			continue
+		}
+		nodeGroup, err := context.CloudProvider.NodeGroupForNode(v.ntbr.Node)
+		if err != nil || nodeGroup == nil || reflect.ValueOf(nodeGroup).IsNil() {
+			continue
+		}
+		id := nodeGroup.Id()
+		if _, found := maxIdleNodes[id]; !found {
+			autoscalingOptions, err := nodeGroup.GetOptions(context.NodeGroupDefaults)
+			if err != nil && err != cloudprovider.ErrNotImplemented {
+				klog.Errorf("Failed to get autoscaling options for node group %s: %v", id, err)
+			}
+			maxIdleNodes[id] = 0
+			if autoscalingOptions != nil {
+				maxIdleNodes[id] = autoscalingOptions.MaxIdleNodes
+			}
+		}
+		if maxIdleNodes[id] > 0 {
+			idleNodes[id] = append(idleNodes[id], v)
+		}
+	}
+
+	result := make(map[string]bool)
+	for id, nodes := range idleNodes {
+		if len(nodes) <= maxIdleNodes[id] {
+			continue
+		}
+		sort.Slice(nodes, func(i, j int) bool {
+			if nodes[i].since.Equal(nodes[j].since) {
+				return nodes[i].ntbr.Node.Name < nodes[j].ntbr.Node.Name
+			}
+			return nodes[i].since.Before(nodes[j].since)
+		})
+		for _, v := range nodes[:len(nodes)-maxIdleNodes[id]] {
+			result[v.ntbr.Node.Name] = true
+		}
+	}
+	return result
+}
+
+func (n *Nodes) unremovableReason(context *context.AutoscalingContext, v *node, ts time.Time, overIdleLimit bool, nodeGroupSize map[string]int, resourcesLeft resource.Limits, resourcesWithLimits []string, as scaledown.ActuationStatus) simulator.UnremovableReason {
 	node := v.ntbr.Node
 	// Check if node is marked with no scale down annotation.
 	if eligibility.HasNoScaleDownAnnotation(node) {
@@ -160,7 +209,9 @@ func (n *Nodes) unremovableReason(context *context.AutoscalingContext, v *node,
 		return simulator.NotAutoscaled
 	}
 
-	if ready {
+	if ready && overIdleLimit {
+		klog.V(4).Infof("%s is an empty node above the max idle nodes of node group %s, skipping unneeded time check", node.Name, nodeGroup.Id())
+	} else if ready {
 		// Check how long a ready node was underutilized.
 		unneededTime, err := n.sdtg.GetScaleDownUnneededTime(nodeGroup)
 		if err != nil {
@@ -211,6 +211,84 @@ func TestRemovableAt(t *testing.T) {
 	}
 }
 
+func TestRemovableAtMaxIdleNodes(t *testing.T) {
+	testCases := []struct {
+		name           string
+		numEmpty       int
+		maxIdleNodes   int
+		minSize        int
+		overIdleLimit  []string
+		wantNumRemoved int
+	}{
+		{
+			name:     "no max idle nodes waits for unneeded time",
+			numEmpty: 3,
+		},
+		{
+			name:           "idle nodes above max idle nodes are removed, oldest first",
+			numEmpty:       4,
+			maxIdleNodes:   1,
+			overIdleLimit:  []string{"empty-0", "empty-1", "empty-2"},
+			wantNumRemoved: 3,
+		},
+		{
+			name:         "idle nodes within max idle nodes wait for unneeded time",
+			numEmpty:     2,
+			maxIdleNodes: 2,
+		},
+		{
+			name:           "idle nodes above max idle nodes respect min size",
+			numEmpty:       4,
+			maxIdleNodes:   1,
+			minSize:        3,
+			overIdleLimit:  []string{"empty-0", "empty-1", "empty-2"},
+			wantNumRemoved: 1,
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			ng := testprovider.NewTestNodeGroup("ng", 100, tc.minSize, tc.numEmpty, true, false, "", nil, nil)
+			ng.SetOptions(&config.NodeGroupAutoscalingOptions{MaxIdleNodes: tc.maxIdleNodes})
+			provider := testprovider.NewTestCloudProvider(nil, nil)
+			provider.InsertNodeGroup(ng)
+
+			now := time.Now()
+			n := NewNodes(&fakeScaleDownTimeGetter{unneededTime: time.Hour}, &resource.LimitsFinder{})
+			for i := 0; i < tc.numEmpty; i++ {
+				node := BuildTestNode(fmt.Sprintf("empty-%d", i), 10, 100)
+				SetNodeReadyState(node, true, now.Add(-time.Hour))
+				provider.AddNode("ng", node)
+				// Nodes found unneeded earlier are added first, keeping the previous timestamps.
+				n.Update(append(nodesToRemove(n), simulator.NodeToBeRemoved{Node: node}), now.Add(time.Duration(i)*time.Second))
+			}
+
+			rsLister, err := kube_util.NewTestReplicaSetLister(nil)
+			assert.NoError(t, err)
+			registry := kube_util.NewListerRegistry(nil, nil, nil, nil, nil, nil, nil, rsLister, nil)
+			ctx, err := NewScaleTestAutoscalingContext(config.AutoscalingOptions{ScaleDownSimulationTimeout: 5 * time.Minute}, &fake.Clientset{}, registry, provider, nil, nil)
+			assert.NoError(t, err)
+
+			as := &fakeActuationStatus{deletionCount: map[string]int{}}
+			gotEmpty, gotDrain, _ := n.RemovableAt(&ctx, now.Add(time.Minute), resource.Limits{}, []string{}, as)
+			assert.Empty(t, gotDrain)
+			gotEmptyRemoved := []string{}
+			for _, ntbr := range gotEmpty {
+				gotEmptyRemoved = append(gotEmptyRemoved, ntbr.Node.Name)
+			}
+			assert.Len(t, gotEmptyRemoved, tc.wantNumRemoved)
+			assert.Subset(t, tc.overIdleLimit, gotEmptyRemoved)
+		})
+	}
+}
+
+func nodesToRemove(n *Nodes) []simulator.NodeToBeRemoved {
+	result := []simulator.NodeToBeRemoved{}
+	for _, v := range n.byName {
+		result = append(result, v.ntbr)
+	}
+	return result
+}
+
 type fakeActuationStatus struct {
 	recentEvictions []*apiv1.Pod
 	deletionCount   map[string]int
@@ -232,10 +310,16 @@ func (f *fakeActuationStatus) DeletionsCount(nodeGroup string) int {
 	return f.deletionCount[nodeGroup]
 }
 
-type fakeScaleDownTimeGetter struct{}
+func (f *fakeActuationStatus) DrainsCount(nodeGroup string) int {
+	return 0
+}
+
+type fakeScaleDownTimeGetter struct {
+	unneededTime time.Duration
+}
 
 func (f *fakeScaleDownTimeGetter) GetScaleDownUnneededTime(cloudprovider.NodeGroup) (time.Duration, error) {
-	return 0 * time.Second, nil
+	return f.unneededTime, nil
 }
 
 func (f *fakeScaleDownTimeGetter) GetScaleDownUnreadyTime(cloudprovider.NodeGroup) (time.Duration, error) {
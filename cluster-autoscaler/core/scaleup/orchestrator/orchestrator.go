@@ -530,9 +530,23 @@ func (o *ScaleUpOrchestrator) SchedulablePods(
 		return []*apiv1.Pod{}
 	}
 
+	excludeTolerateAllPods := false
+	if len(nodeInfo.Node().Spec.Taints) > 0 {
+		autoscalingOptions, err := nodeGroup.GetOptions(o.autoscalingContext.NodeGroupDefaults)
+		if err != nil && err != cloudprovider.ErrNotImplemented {
+			klog.Errorf("Failed to get autoscaling options for node group %s: %v", nodeGroup.Id(), err)
+		}
+		excludeTolerateAllPods = autoscalingOptions != nil && autoscalingOptions.ExcludeTolerateAllPods
+	}
+
 	var schedulablePods []*apiv1.Pod
 	for _, eg := range podEquivalenceGroups {
 		samplePod := eg.Pods[0]
+		if excludeTolerateAllPods && toleratesAllTaints(samplePod) {
+			klog.V(2).Infof("Pod %s/%s tolerates all taints, not considering it for scale-up of tainted node group %s", samplePod.Namespace, samplePod.Name, nodeGroup.Id())
+			eg.SchedulingErrors[nodeGroup.Id()] = TolerateAllPodsExcludedReason
+			continue
+		}
 		if err := o.autoscalingContext.PredicateChecker.CheckPredicates(o.autoscalingContext.ClusterSnapshot, samplePod, nodeInfo.Node().Name); err == nil {
 			// Add pods to option.
 			schedulablePods = append(schedulablePods, eg.Pods...)
@@ -550,6 +564,17 @@ func (o *ScaleUpOrchestrator) SchedulablePods(
 	return schedulablePods
 }
 
+// toleratesAllTaints returns true if the pod has a toleration matching every taint, i.e. one with
+// an empty key and the Exists operator.
+func toleratesAllTaints(pod *apiv1.Pod) bool {
+	for _, toleration := range pod.Spec.Tolerations {
+		if toleration.Key == "" && toleration.Operator == apiv1.TolerationOpExists && toleration.Effect == "" {
+			return true
+		}
+	}
+	return false
+}
+
 // UpcomingNodes returns a list of nodes that are not ready but should be.
 func (o *ScaleUpOrchestrator) UpcomingNodes(nodeInfos map[string]*schedulerframework.NodeInfo) ([]*schedulerframework.NodeInfo, errors.AutoscalerError) {
 	upcomingCounts, _ := o.clusterStateRegistry.GetUpcomingNodes()
@@ -35,6 +35,7 @@ import (
 	"k8s.io/autoscaler/cluster-autoscaler/clusterstate"
 	"k8s.io/autoscaler/cluster-autoscaler/config"
 	"k8s.io/autoscaler/cluster-autoscaler/context"
+	"k8s.io/autoscaler/cluster-autoscaler/core/scaleup/equivalence"
 	"k8s.io/autoscaler/cluster-autoscaler/core/scaleup/resource"
 	. "k8s.io/autoscaler/cluster-autoscaler/core/test"
 	"k8s.io/autoscaler/cluster-autoscaler/core/utils"
@@ -1193,6 +1194,69 @@ func TestScaleUpNoHelp(t *testing.T) {
 	assert.Regexp(t, regexp.MustCompile("NotTriggerScaleUp"), event)
 }
 
+func TestSchedulablePodsExcludeTolerateAllPods(t *testing.T) {
+	tolerateAll := BuildTestPod("tolerate-all", 80, 0)
+	tolerateAll.Spec.Tolerations = []apiv1.Toleration{{Operator: apiv1.TolerationOpExists}}
+	tolerateTaint := BuildTestPod("tolerate-taint", 80, 0)
+	tolerateTaint.Spec.Tolerations = []apiv1.Toleration{{Key: "dedicated", Operator: apiv1.TolerationOpExists}}
+
+	for _, tc := range []struct {
+		name                   string
+		taints                 []apiv1.Taint
+		excludeTolerateAllPods bool
+		wantSchedulable        []string
+	}{
+		{
+			name:            "tolerate-all pods scale up tainted node group by default",
+			taints:          []apiv1.Taint{{Key: "dedicated", Value: "reserved", Effect: apiv1.TaintEffectNoSchedule}},
+			wantSchedulable: []string{"tolerate-all", "tolerate-taint"},
+		},
+		{
+			name:                   "tolerate-all pods excluded from tainted node group",
+			taints:                 []apiv1.Taint{{Key: "dedicated", Value: "reserved", Effect: apiv1.TaintEffectNoSchedule}},
+			excludeTolerateAllPods: true,
+			wantSchedulable:        []string{"tolerate-taint"},
+		},
+		{
+			name:                   "tolerate-all pods scale up untainted node group",
+			excludeTolerateAllPods: true,
+			wantSchedulable:        []string{"tolerate-all", "tolerate-taint"},
+		},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			provider := testprovider.NewTestCloudProvider(nil, nil)
+			ng := provider.BuildNodeGroup("ng1", 0, 10, 0, false, "", &config.NodeGroupAutoscalingOptions{ExcludeTolerateAllPods: tc.excludeTolerateAllPods})
+			provider.InsertNodeGroup(ng)
+
+			options := config.AutoscalingOptions{
+				EstimatorName:  estimator.BinpackingEstimatorName,
+				MaxCoresTotal:  config.DefaultMaxClusterCores,
+				MaxMemoryTotal: config.DefaultMaxClusterMemory,
+			}
+			listers := kube_util.NewListerRegistry(nil, nil, kube_util.NewTestPodLister(nil), nil, nil, nil, nil, nil, nil)
+			context, err := NewScaleTestAutoscalingContext(options, &fake.Clientset{}, listers, provider, nil, nil)
+			assert.NoError(t, err)
+
+			node := BuildTestNode("ng1-template", 1000, 1000)
+			SetNodeReadyState(node, true, time.Now())
+			node.Spec.Taints = tc.taints
+			nodeInfo := schedulerframework.NewNodeInfo()
+			nodeInfo.SetNode(node)
+
+			suOrchestrator := New()
+			suOrchestrator.Initialize(&context, NewTestProcessors(&context), nil, taints.TaintConfig{})
+			podGroups := equivalence.BuildPodGroups([]*apiv1.Pod{tolerateAll, tolerateTaint})
+			schedulable := suOrchestrator.(*ScaleUpOrchestrator).SchedulablePods(podGroups, ng, nodeInfo)
+
+			var names []string
+			for _, pod := range schedulable {
+				names = append(names, pod.Name)
+			}
+			assert.ElementsMatch(t, tc.wantSchedulable, names)
+		})
+	}
+}
+
 type constNodeGroupSetProcessor struct {
 	similarNodeGroups []cloudprovider.NodeGroup
 }
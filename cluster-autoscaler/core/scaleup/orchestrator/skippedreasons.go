@@ -43,6 +43,8 @@ var (
 	MaxLimitReachedReason = NewSkippedReasons("max node group size reached")
 	// NotReadyReason node group is not ready.
 	NotReadyReason = NewSkippedReasons("not ready for scale-up")
+	// TolerateAllPodsExcludedReason pods tolerating all taints are excluded from the node group scale-up.
+	TolerateAllPodsExcludedReason = NewSkippedReasons("pods tolerating all taints are excluded from tainted node group")
 )
 
 // MaxResourceLimitReached contains information why given node group was skipped.
@@ -48,6 +48,7 @@ import (
 	"k8s.io/autoscaler/cluster-autoscaler/expander"
 	"k8s.io/autoscaler/cluster-autoscaler/metrics"
 	ca_processors "k8s.io/autoscaler/cluster-autoscaler/processors"
+	"k8s.io/autoscaler/cluster-autoscaler/processors/nodegroupset"
 	"k8s.io/autoscaler/cluster-autoscaler/processors/status"
 	"k8s.io/autoscaler/cluster-autoscaler/simulator"
 	"k8s.io/autoscaler/cluster-autoscaler/simulator/clustersnapshot"
@@ -149,6 +150,8 @@ func NewStaticAutoscaler(
 	clusterStateConfig := clusterstate.ClusterStateRegistryConfig{
 		MaxTotalUnreadyPercentage: opts.MaxTotalUnreadyPercentage,
 		OkTotalUnreadyCount:       opts.OkTotalUnreadyCount,
+
+		ResetBackoffOnInstanceRegistration: opts.ResetBackoffOnInstanceRegistration,
 	}
 	clusterStateRegistry := clusterstate.NewClusterStateRegistry(cloudProvider, clusterStateConfig, autoscalingKubeClients.LogRecorder, backoff, processors.NodeGroupConfigProcessor)
 	processorCallbacks := newStaticAutoscalerProcessorCallbacks()
@@ -366,6 +369,15 @@ func (a *StaticAutoscaler) RunOnce(currentTime time.Time) caerrors.AutoscalerErr
 	}
 
 	a.DebuggingSnapshotter.SetTemplateNodes(nodeInfosForGroups)
+	if a.DebuggingSnapshotter.IsDataCollectionAllowed() {
+		excludedFromBalancing := []string{}
+		for _, nodeGroup := range a.CloudProvider.NodeGroups() {
+			if nodegroupset.IsExcludedFromBalancing(autoscalingContext, nodeGroup) {
+				excludedFromBalancing = append(excludedFromBalancing, nodeGroup.Id())
+			}
+		}
+		a.DebuggingSnapshotter.SetNodeGroupsExcludedFromBalancing(excludedFromBalancing)
+	}
 
 	nodeInfosForGroups, err = a.processors.NodeInfoProcessor.Process(autoscalingContext, nodeInfosForGroups)
 	if err != nil {
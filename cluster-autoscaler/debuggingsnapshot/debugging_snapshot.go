@@ -42,6 +42,9 @@ type DebuggingSnapshot interface {
 	// SetTemplateNodes is a setter for all the TemplateNodes present in the cluster
 	// incl. templates for which there are no nodes
 	SetTemplateNodes(map[string]*framework.NodeInfo)
+	// SetNodeGroupsExcludedFromBalancing is a setter for the ids of node groups
+	// which opted out of balancing with similar node groups
+	SetNodeGroupsExcludedFromBalancing([]string)
 	// SetErrorMessage sets the error message in the snapshot
 	SetErrorMessage(string)
 	// SetEndTimestamp sets the timestamp in the snapshot,
@@ -61,12 +64,13 @@ type DebuggingSnapshot interface {
 // Please add all new output fields in this struct. This is to make the data
 // encoding/decoding easier as the single object going into the decoder
 type DebuggingSnapshotImpl struct {
-	NodeList                      []*ClusterNode          `json:"NodeList"`
-	UnscheduledPodsCanBeScheduled []*v1.Pod               `json:"UnscheduledPodsCanBeScheduled"`
-	Error                         string                  `json:"Error,omitempty"`
-	StartTimestamp                time.Time               `json:"StartTimestamp"`
-	EndTimestamp                  time.Time               `json:"EndTimestamp"`
-	TemplateNodes                 map[string]*ClusterNode `json:"TemplateNodes"`
+	NodeList                        []*ClusterNode          `json:"NodeList"`
+	UnscheduledPodsCanBeScheduled   []*v1.Pod               `json:"UnscheduledPodsCanBeScheduled"`
+	Error                           string                  `json:"Error,omitempty"`
+	StartTimestamp                  time.Time               `json:"StartTimestamp"`
+	EndTimestamp                    time.Time               `json:"EndTimestamp"`
+	TemplateNodes                   map[string]*ClusterNode `json:"TemplateNodes"`
+	NodeGroupsExcludedFromBalancing []string                `json:"NodeGroupsExcludedFromBalancing,omitempty"`
 }
 
 // SetUnscheduledPodsCanBeScheduled is the setter for UnscheduledPodsCanBeScheduled
@@ -93,6 +97,11 @@ func (s *DebuggingSnapshotImpl) SetTemplateNodes(templates map[string]*framework
 	}
 }
 
+// SetNodeGroupsExcludedFromBalancing is the setter for NodeGroupsExcludedFromBalancing
+func (s *DebuggingSnapshotImpl) SetNodeGroupsExcludedFromBalancing(nodeGroupIds []string) {
+	s.NodeGroupsExcludedFromBalancing = append([]string(nil), nodeGroupIds...)
+}
+
 // GetClusterNodeCopy is an util func to copy template node and filter values
 func GetClusterNodeCopy(template *framework.NodeInfo) *ClusterNode {
 	cNode := &ClusterNode{}
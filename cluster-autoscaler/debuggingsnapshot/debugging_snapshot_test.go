@@ -102,3 +102,18 @@ func TestEmptyDataNoError(t *testing.T) {
 	assert.False(t, err)
 	assert.NotNil(t, op)
 }
+
+func TestNodeGroupsExcludedFromBalancing(t *testing.T) {
+	snapshot := &DebuggingSnapshotImpl{}
+	op, err := snapshot.GetOutputBytes()
+	assert.False(t, err)
+	var parsed map[string]interface{}
+	assert.NoError(t, json.Unmarshal(op, &parsed))
+	assert.NotContains(t, parsed, "NodeGroupsExcludedFromBalancing")
+
+	snapshot.SetNodeGroupsExcludedFromBalancing([]string{"ng1", "ng2"})
+	op, err = snapshot.GetOutputBytes()
+	assert.False(t, err)
+	assert.NoError(t, json.Unmarshal(op, &parsed))
+	assert.Equal(t, []interface{}{"ng1", "ng2"}, parsed["NodeGroupsExcludedFromBalancing"])
+}
@@ -82,6 +82,9 @@ type DebuggingSnapshotter interface {
 	// SetTemplateNodes is a setter for all the TemplateNodes present in the cluster
 	// incl. templates for which there are no nodes
 	SetTemplateNodes(map[string]*framework.NodeInfo)
+	// SetNodeGroupsExcludedFromBalancing is a setter for the ids of node groups
+	// which opted out of balancing with similar node groups
+	SetNodeGroupsExcludedFromBalancing([]string)
 	// ResponseHandler is the http response handler to manage incoming requests
 	ResponseHandler(http.ResponseWriter, *http.Request)
 	// IsDataCollectionAllowed checks the internal State of the snapshotter
@@ -251,6 +254,17 @@ func (d *DebuggingSnapshotterImpl) SetTemplateNodes(templates map[string]*framew
 	d.DebuggingSnapshot.SetTemplateNodes(templates)
 }
 
+// SetNodeGroupsExcludedFromBalancing is the setter for NodeGroupsExcludedFromBalancing
+func (d *DebuggingSnapshotterImpl) SetNodeGroupsExcludedFromBalancing(nodeGroupIds []string) {
+	d.Mutex.Lock()
+	defer d.Mutex.Unlock()
+	if !d.IsDataCollectionAllowedNoLock() {
+		return
+	}
+	klog.V(4).Infof("NodeGroupsExcludedFromBalancing is being set for the debugging snapshot")
+	d.DebuggingSnapshot.SetNodeGroupsExcludedFromBalancing(nodeGroupIds)
+}
+
 // Cleanup clears the internal data sets of the cluster
 func (d *DebuggingSnapshotterImpl) Cleanup() {
 	if d.CancelRequest != nil {
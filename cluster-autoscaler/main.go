@@ -55,6 +55,7 @@ import (
 	"k8s.io/autoscaler/cluster-autoscaler/processors/scaledowncandidates"
 	"k8s.io/autoscaler/cluster-autoscaler/processors/scaledowncandidates/emptycandidates"
 	"k8s.io/autoscaler/cluster-autoscaler/processors/scaledowncandidates/previouscandidates"
+	"k8s.io/autoscaler/cluster-autoscaler/processors/status"
 	"k8s.io/autoscaler/cluster-autoscaler/simulator/clustersnapshot"
 	"k8s.io/autoscaler/cluster-autoscaler/simulator/drainability/rules"
 	"k8s.io/autoscaler/cluster-autoscaler/simulator/options"
@@ -230,6 +231,8 @@ var (
 		"maxNodeGroupBackoffDuration is the maximum backoff duration for a NodeGroup after new nodes failed to start.")
 	nodeGroupBackoffResetTimeout = flag.Duration("node-group-backoff-reset-timeout", 3*time.Hour,
 		"nodeGroupBackoffResetTimeout is the time after last failed scale-up when the backoff duration is reset.")
+	resetBackoffOnInstanceRegistration = flag.Bool("reset-backoff-on-instance-registration", false,
+		"resetBackoffOnInstanceRegistration resets the backoff of a NodeGroup as soon as one of its new instances finishes creating, instead of waiting for the backoff to expire.")
 	maxScaleDownParallelismFlag             = flag.Int("max-scale-down-parallelism", 10, "Maximum number of nodes (both empty and needing drain) that can be deleted in parallel.")
 	maxDrainParallelismFlag                 = flag.Int("max-drain-parallelism", 1, "Maximum number of nodes needing drain, that can be drained and deleted in parallel.")
 	recordDuplicatedEvents                  = flag.Bool("record-duplicated-events", false, "enable duplication of similar events within a 5 minute window.")
@@ -253,7 +256,10 @@ var (
 			"--max-graceful-termination-sec flag should not be set when this flag is set. Not setting this flag will use unordered evictor by default."+
 			"Priority evictor reuses the concepts of drain logic in kubelet(https://github.com/kubernetes/enhancements/tree/master/keps/sig-node/2712-pod-priority-based-graceful-node-shutdown#migration-from-the-node-graceful-shutdown-feature)."+
 			"Eg. flag usage:  '10000:20,1000:100,0:60'")
-	provisioningRequestsEnabled = flag.Bool("enable-provisioning-requests", false, "Whether the clusterautoscaler will be handling the ProvisioningRequest CRs.")
+	provisioningRequestsEnabled         = flag.Bool("enable-provisioning-requests", false, "Whether the clusterautoscaler will be handling the ProvisioningRequest CRs.")
+	provisioningRequestPodAnnotationKey = flag.String("provisioning-request-pod-annotation-key", "cluster-autoscaler.kubernetes.io/consume-provisioning-request", "Annotation of the pods naming the ProvisioningRequest they consume.")
+	provisioningRequestExternalClasses  = pflag.StringSlice("provisioning-request-external-classes", []string{}, "Provisioning classes of the ProvisioningRequests handled by an external provisioner. Pods consuming ProvisioningRequests of other classes trigger normal scale-ups. If empty, pods consuming any ProvisioningRequest don't trigger scale-ups.")
+	scaleDownBlockedWarningIterations   = flag.Int("scale-down-blocked-warning-iterations", 0, "Number of consecutive iterations a node group can be kept above its min size by idle nodes that can't be removed (e.g. blocked by a PDB, local storage or a system pod) before a warning event is emitted. Set to 0 to disable the warnings.")
 )
 
 func isFlagPassed(name string) bool {
@@ -405,6 +411,7 @@ func createAutoscalingOptions() config.AutoscalingOptions {
 		InitialNodeGroupBackoffDuration:    *initialNodeGroupBackoffDuration,
 		MaxNodeGroupBackoffDuration:        *maxNodeGroupBackoffDuration,
 		NodeGroupBackoffResetTimeout:       *nodeGroupBackoffResetTimeout,
+		ResetBackoffOnInstanceRegistration: *resetBackoffOnInstanceRegistration,
 		MaxScaleDownParallelism:            *maxScaleDownParallelismFlag,
 		MaxDrainParallelism:                *maxDrainParallelismFlag,
 		RecordDuplicatedEvents:             *recordDuplicatedEvents,
@@ -426,6 +433,9 @@ func createAutoscalingOptions() config.AutoscalingOptions {
 		DynamicNodeDeleteDelayAfterTaintEnabled: *dynamicNodeDeleteDelayAfterTaintEnabled,
 		BypassedSchedulers:                      scheduler_util.GetBypassedSchedulersMap(*bypassedSchedulers),
 		ProvisioningRequestEnabled:              *provisioningRequestsEnabled,
+		ProvisioningRequestPodAnnotationKey:     *provisioningRequestPodAnnotationKey,
+		ProvisioningRequestExternalClasses:      *provisioningRequestExternalClasses,
+		ScaleDownBlockedWarningIterations:       *scaleDownBlockedWarningIterations,
 	}
 }
 
@@ -483,9 +493,13 @@ func buildAutoscaler(debuggingSnapshotter debuggingsnapshot.DebuggingSnapshotter
 	opts.Processors.TemplateNodeInfoProvider = nodeinfosprovider.NewDefaultTemplateNodeInfoProvider(nodeInfoCacheExpireTime, *forceDaemonSets)
 	podListProcessor := podlistprocessor.NewDefaultPodListProcessor(opts.PredicateChecker)
 	if autoscalingOptions.ProvisioningRequestEnabled {
-		podListProcessor.AddProcessor(provreq.NewProvisioningRequestPodsFilter(provreq.NewDefautlEventManager()))
+		podListProcessor.AddProcessor(provreq.NewProvisioningRequestPodsFilter(provreq.NewDefautlEventManager(),
+			autoscalingOptions.ProvisioningRequestPodAnnotationKey, autoscalingOptions.ProvisioningRequestExternalClasses))
 	}
 	opts.Processors.PodListProcessor = podListProcessor
+	if autoscalingOptions.ScaleDownBlockedWarningIterations > 0 {
+		opts.Processors.ScaleDownStatusProcessor = status.NewBlockedScaleDownStatusProcessor(autoscalingOptions.ScaleDownBlockedWarningIterations)
+	}
 	scaleDownCandidatesComparers := []scaledowncandidates.CandidatesComparer{}
 	if autoscalingOptions.ParallelDrain {
 		sdCandidatesSorting := previouscandidates.NewPreviousCandidates()
@@ -498,6 +512,7 @@ func buildAutoscaler(debuggingSnapshotter debuggingsnapshot.DebuggingSnapshotter
 
 	cp := scaledowncandidates.NewCombinedScaleDownCandidatesProcessor()
 	cp.Register(scaledowncandidates.NewScaleDownCandidatesSortingProcessor(scaleDownCandidatesComparers))
+	cp.Register(scaledowncandidates.NewScaleDownCandidatesDeferralProcessor())
 
 	if autoscalingOptions.ScaleDownDelayTypeLocal {
 		sdp := scaledowncandidates.NewScaleDownCandidatesDelayProcessor()
@@ -365,6 +365,24 @@ var (
 		[]string{"direction", "reason"},
 	)
 
+	ignoredProvisioningRequestPodsCount = k8smetrics.NewCounterVec(
+		&k8smetrics.CounterOpts{
+			Namespace: caNamespace,
+			Name:      "ignored_provisioning_request_pods_total",
+			Help:      "Number of unschedulable pods left to an external provisioner as they consume a ProvisioningRequest, by provisioning class.",
+		},
+		[]string{"provisioning_class"},
+	)
+
+	blockedScaleDownWarningsCount = k8smetrics.NewCounterVec(
+		&k8smetrics.CounterOpts{
+			Namespace: caNamespace,
+			Name:      "blocked_scale_down_warnings_count",
+			Help:      "Count of warnings about idle nodes keeping their node group above min size, by blocking reason.",
+		},
+		[]string{"reason"},
+	)
+
 	/**** Metrics related to NodeAutoprovisioning ****/
 	napEnabled = k8smetrics.NewGauge(
 		&k8smetrics.GaugeOpts{
@@ -428,6 +446,8 @@ func RegisterAll(emitPerNodeGroupMetrics bool) {
 	legacyregistry.MustRegister(oldUnregisteredNodesRemovedCount)
 	legacyregistry.MustRegister(overflowingControllersCount)
 	legacyregistry.MustRegister(skippedScaleEventsCount)
+	legacyregistry.MustRegister(blockedScaleDownWarningsCount)
+	legacyregistry.MustRegister(ignoredProvisioningRequestPodsCount)
 	legacyregistry.MustRegister(napEnabled)
 	legacyregistry.MustRegister(nodeGroupCreationCount)
 	legacyregistry.MustRegister(nodeGroupDeletionCount)
@@ -641,6 +661,18 @@ func RegisterSkippedScaleDownMemory() {
 	skippedScaleEventsCount.WithLabelValues(DirectionScaleDown, MemoryResourceLimit).Add(1.0)
 }
 
+// RegisterBlockedScaleDownWarning increases the count of warnings about idle nodes which can't be removed
+// although their node group is above min size
+func RegisterBlockedScaleDownWarning(reason string) {
+	blockedScaleDownWarningsCount.WithLabelValues(reason).Add(1.0)
+}
+
+// RegisterIgnoredProvisioningRequestPod increases the count of unschedulable pods consuming a ProvisioningRequest
+// of the given class, which don't trigger scale-ups
+func RegisterIgnoredProvisioningRequestPod(provisioningClass string) {
+	ignoredProvisioningRequestPodsCount.WithLabelValues(provisioningClass).Add(1.0)
+}
+
 // RegisterSkippedScaleUpCPU increases the count of skipped scale outs because of CPU resource limits
 func RegisterSkippedScaleUpCPU() {
 	skippedScaleEventsCount.WithLabelValues(DirectionScaleUp, CpuResourceLimit).Add(1.0)
@@ -46,11 +46,18 @@ func (b *BalancingNodeGroupSetProcessor) FindSimilarNodeGroups(context *context.
 			"failed to find template node for node group %s",
 			nodeGroupId)
 	}
+	if IsExcludedFromBalancing(context, nodeGroup) {
+		klog.V(4).Infof("Node group %s is excluded from balancing", nodeGroupId)
+		return result, nil
+	}
 	for _, ng := range context.CloudProvider.NodeGroups() {
 		ngId := ng.Id()
 		if ngId == nodeGroupId {
 			continue
 		}
+		if IsExcludedFromBalancing(context, ng) {
+			continue
+		}
 		ngNodeInfo, found := nodeInfosForGroups[ngId]
 		if !found {
 			klog.Warningf("Failed to find nodeInfo for group %v", ngId)
@@ -67,6 +74,17 @@ func (b *BalancingNodeGroupSetProcessor) FindSimilarNodeGroups(context *context.
 	return result, nil
 }
 
+// IsExcludedFromBalancing returns true if the node group opted out of being balanced
+// with similar node groups.
+func IsExcludedFromBalancing(context *context.AutoscalingContext, nodeGroup cloudprovider.NodeGroup) bool {
+	autoscalingOptions, err := nodeGroup.GetOptions(context.NodeGroupDefaults)
+	if err != nil && err != cloudprovider.ErrNotImplemented {
+		klog.Errorf("Failed to get autoscaling options for node group %s: %v", nodeGroup.Id(), err)
+		return false
+	}
+	return autoscalingOptions != nil && autoscalingOptions.ExcludeFromBalancing
+}
+
 // BalanceScaleUpBetweenGroups distributes a given number of nodes between
 // given set of NodeGroups. The nodes are added to smallest group first, trying
 // to make the group sizes as evenly balanced as possible.
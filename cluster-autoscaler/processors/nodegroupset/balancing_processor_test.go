@@ -19,6 +19,7 @@ package nodegroupset
 import (
 	"testing"
 
+	apiv1 "k8s.io/api/core/v1"
 	schedulerframework "k8s.io/kubernetes/pkg/scheduler/framework"
 
 	"k8s.io/autoscaler/cluster-autoscaler/cloudprovider"
@@ -112,6 +113,49 @@ func TestFindSimilarNodeGroupsCustomComparator(t *testing.T) {
 	basicSimilarNodeGroupsTest(t, context, processor, ni1, ni2, ni3)
 }
 
+func TestFindSimilarNodeGroupsExcludedFromBalancing(t *testing.T) {
+	n1 := BuildTestNode("n1", 1000, 1000)
+	n2 := BuildTestNode("n2", 1000, 1000)
+	n3 := BuildTestNode("n3", 1000, 1000)
+	provider := testprovider.NewTestCloudProvider(nil, nil)
+	provider.AddNodeGroup("ng1", 1, 10, 1)
+	provider.AddNodeGroupWithCustomOptions("ng2", 1, 10, 1, &config.NodeGroupAutoscalingOptions{ExcludeFromBalancing: true})
+	provider.AddNodeGroupWithCustomOptions("ng3", 1, 10, 1, &config.NodeGroupAutoscalingOptions{ExcludeFromBalancing: false})
+	provider.AddNode("ng1", n1)
+	provider.AddNode("ng2", n2)
+	provider.AddNode("ng3", n3)
+	context := &context.AutoscalingContext{CloudProvider: provider}
+
+	nodeInfosForGroups := map[string]*schedulerframework.NodeInfo{}
+	for _, n := range []*apiv1.Node{n1, n2, n3} {
+		nodeInfo := schedulerframework.NewNodeInfo()
+		nodeInfo.SetNode(n)
+		ng, _ := provider.NodeGroupForNode(n)
+		nodeInfosForGroups[ng.Id()] = nodeInfo
+	}
+	ng1, _ := provider.NodeGroupForNode(n1)
+	ng2, _ := provider.NodeGroupForNode(n2)
+	ng3, _ := provider.NodeGroupForNode(n3)
+
+	processor := NewDefaultNodeGroupSetProcessor([]string{}, config.NodeGroupDifferenceRatios{})
+
+	similar, err := processor.FindSimilarNodeGroups(context, ng1, nodeInfosForGroups)
+	assert.NoError(t, err)
+	assert.Equal(t, []cloudprovider.NodeGroup{ng3}, similar)
+
+	similar, err = processor.FindSimilarNodeGroups(context, ng2, nodeInfosForGroups)
+	assert.NoError(t, err)
+	assert.Equal(t, []cloudprovider.NodeGroup{}, similar)
+
+	similar, err = processor.FindSimilarNodeGroups(context, ng3, nodeInfosForGroups)
+	assert.NoError(t, err)
+	assert.Equal(t, []cloudprovider.NodeGroup{ng1}, similar)
+
+	assert.False(t, IsExcludedFromBalancing(context, ng1))
+	assert.True(t, IsExcludedFromBalancing(context, ng2))
+	assert.False(t, IsExcludedFromBalancing(context, ng3))
+}
+
 func TestBalanceSingleGroup(t *testing.T) {
 	processor := NewDefaultNodeGroupSetProcessor([]string{}, config.NodeGroupDifferenceRatios{})
 	context := &context.AutoscalingContext{}
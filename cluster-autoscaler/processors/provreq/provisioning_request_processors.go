@@ -23,13 +23,17 @@ import (
 	apiv1 "k8s.io/api/core/v1"
 	v1 "k8s.io/api/core/v1"
 	"k8s.io/autoscaler/cluster-autoscaler/context"
+	"k8s.io/autoscaler/cluster-autoscaler/metrics"
 	"k8s.io/autoscaler/cluster-autoscaler/processors/pods"
 	"k8s.io/autoscaler/cluster-autoscaler/utils/klogx"
+	"k8s.io/klog/v2"
 )
 
 const (
 	provisioningRequestPodAnnotationKey = "cluster-autoscaler.kubernetes.io/consume-provisioning-request"
+	provisioningClassPodAnnotationKey   = "cluster-autoscaler.kubernetes.io/provisioning-class-name"
 	maxProvReqEvent                     = 50
+	provReqEventWindow                  = time.Minute
 )
 
 // EventManager is an interface for handling events for provisioning request.
@@ -41,30 +45,57 @@ type EventManager interface {
 type defaultEventManager struct {
 	loggedEvents int
 	limit        int
+	// window is the sliding window in which at most limit events are emitted. If zero, at most limit
+	// events are emitted per loop.
+	window     time.Duration
+	eventTimes []time.Time
 }
 
 // NewDefautlEventManager return basic event manager.
 func NewDefautlEventManager() *defaultEventManager {
-	return &defaultEventManager{limit: maxProvReqEvent}
+	return &defaultEventManager{limit: maxProvReqEvent, window: provReqEventWindow}
 }
 
 // LogIgnoredInScaleUpEvent adds event about ignored scale up for unscheduled pod, that consumes Provisioning Request.
 func (e *defaultEventManager) LogIgnoredInScaleUpEvent(context *context.AutoscalingContext, now time.Time, pod *apiv1.Pod, prName string) {
-	message := fmt.Sprintf("Unschedulable pod didn't trigger scale-up, because it's consuming ProvisioningRequest %s/%s", pod.Namespace, prName)
+	if e.window > 0 {
+		e.expireEvents(now)
+	}
+	message := fmt.Sprintf("Unschedulable pod %s/%s didn't trigger scale-up, because it's consuming ProvisioningRequest %s/%s", pod.Namespace, pod.Name, pod.Namespace, prName)
 	if e.loggedEvents < e.limit {
 		context.Recorder.Event(pod, apiv1.EventTypeNormal, "", message)
 		e.loggedEvents++
+		if e.window > 0 {
+			e.eventTimes = append(e.eventTimes, now)
+		}
+	}
+}
+
+// expireEvents forgets the events emitted before the sliding window ending at now.
+func (e *defaultEventManager) expireEvents(now time.Time) {
+	expired := 0
+	for expired < len(e.eventTimes) && now.Sub(e.eventTimes[expired]) >= e.window {
+		expired++
 	}
+	e.eventTimes = e.eventTimes[expired:]
+	e.loggedEvents = len(e.eventTimes)
 }
 
 // Reset resets event manager internal structure. It will be called once before handling all pods.
+// Events emitted in a sliding window are kept across loops.
 func (e *defaultEventManager) Reset() {
-	e.loggedEvents = 0
+	if e.window == 0 {
+		e.loggedEvents = 0
+	}
 }
 
 // ProvisioningRequestPodsFilter filter out pods that consumes Provisioning Request
 type ProvisioningRequestPodsFilter struct {
 	eventManager EventManager
+	// annotationKey is the pod annotation naming the consumed ProvisioningRequest.
+	annotationKey string
+	// externalClasses are the provisioning classes handled by an external provisioner, all of them if empty.
+	externalClasses map[string]bool
 }
 
 // Process filters out all pods that are consuming a Provisioning Request from unschedulable pods list.
@@ -76,31 +107,54 @@ func (p *ProvisioningRequestPodsFilter) Process(
 	p.eventManager.Reset()
 	loggingQuota := klogx.PodsLoggingQuota()
 	result := make([]*apiv1.Pod, 0, len(unschedulablePods))
+	ignoredPods := make(map[string]int)
 	for _, pod := range unschedulablePods {
-		prName, found := provisioningRequestName(pod)
-		if !found {
+		prName, found := provisioningRequestName(pod, p.annotationKey)
+		if !found || !p.externallyProvisioned(pod) {
 			result = append(result, pod)
 			continue
 		}
 		klogx.V(1).UpTo(loggingQuota).Infof("Ignoring unschedulable pod %s/%s as it consumes ProvisioningRequest: %s/%s", pod.Namespace, pod.Name, pod.Namespace, prName)
 		p.eventManager.LogIgnoredInScaleUpEvent(context, now, pod, prName)
+		ignoredPods[fmt.Sprintf("%s/%s", pod.Namespace, prName)]++
+		metrics.RegisterIgnoredProvisioningRequestPod(pod.Annotations[provisioningClassPodAnnotationKey])
 	}
 	klogx.V(1).Over(loggingQuota).Infof("There are also %v other pods which were ignored", -loggingQuota.Left())
+	for provReq, count := range ignoredPods {
+		klog.V(1).Infof("Ignored %d unschedulable pods consuming ProvisioningRequest %s", count, provReq)
+	}
 	return result, nil
 }
 
 // CleanUp cleans up the processor's internal structures.
 func (p *ProvisioningRequestPodsFilter) CleanUp() {}
 
-// NewProvisioningRequestPodsFilter creates a ProvisioningRequest filter processor.
-func NewProvisioningRequestPodsFilter(e EventManager) pods.PodListProcessor {
-	return &ProvisioningRequestPodsFilter{e}
+// NewProvisioningRequestPodsFilter creates a ProvisioningRequest filter processor, for the pods naming the ProvisioningRequest
+// they consume with the given annotation (cluster-autoscaler.kubernetes.io/consume-provisioning-request if empty).
+// Only the pods consuming ProvisioningRequests of the given external classes are filtered out, or all of them if none is given.
+func NewProvisioningRequestPodsFilter(e EventManager, annotationKey string, externalClasses []string) pods.PodListProcessor {
+	if annotationKey == "" {
+		annotationKey = provisioningRequestPodAnnotationKey
+	}
+	classes := make(map[string]bool, len(externalClasses))
+	for _, class := range externalClasses {
+		classes[class] = true
+	}
+	return &ProvisioningRequestPodsFilter{eventManager: e, annotationKey: annotationKey, externalClasses: classes}
+}
+
+// externallyProvisioned tells if the ProvisioningRequest consumed by the pod is handled by an external provisioner.
+func (p *ProvisioningRequestPodsFilter) externallyProvisioned(pod *v1.Pod) bool {
+	if len(p.externalClasses) == 0 {
+		return true
+	}
+	return p.externalClasses[pod.Annotations[provisioningClassPodAnnotationKey]]
 }
 
-func provisioningRequestName(pod *v1.Pod) (string, bool) {
+func provisioningRequestName(pod *v1.Pod, annotationKey string) (string, bool) {
 	if pod == nil || pod.Annotations == nil {
 		return "", false
 	}
-	provReqName, found := pod.Annotations[provisioningRequestPodAnnotationKey]
+	provReqName, found := pod.Annotations[annotationKey]
 	return provReqName, found
 }
@@ -18,6 +18,8 @@ package provreq
 
 import (
 	"fmt"
+	"net/http"
+	"net/http/httptest"
 	"testing"
 	"time"
 
@@ -25,8 +27,10 @@ import (
 	apiv1 "k8s.io/api/core/v1"
 	v1 "k8s.io/api/core/v1"
 	"k8s.io/autoscaler/cluster-autoscaler/context"
+	"k8s.io/autoscaler/cluster-autoscaler/metrics"
 	. "k8s.io/autoscaler/cluster-autoscaler/utils/test"
 	"k8s.io/client-go/tools/record"
+	"k8s.io/component-base/metrics/legacyregistry"
 )
 
 func TestProvisioningRequestPodsFilter(t *testing.T) {
@@ -67,13 +71,13 @@ func TestProvisioningRequestPodsFilter(t *testing.T) {
 	for _, test := range testCases {
 		eventRecorder := record.NewFakeRecorder(10)
 		ctx := &context.AutoscalingContext{AutoscalingKubeClients: context.AutoscalingKubeClients{Recorder: eventRecorder}}
-		filter := NewProvisioningRequestPodsFilter(NewDefautlEventManager())
+		filter := NewProvisioningRequestPodsFilter(NewDefautlEventManager(), "", nil)
 		got, _ := filter.Process(ctx, test.unschedulableCandidates)
 		assert.ElementsMatch(t, got, test.expectedUnscheduledPods)
-		if len(test.expectedUnscheduledPods) < len(test.expectedUnscheduledPods) {
+		if len(test.expectedUnscheduledPods) < len(test.unschedulableCandidates) {
 			select {
 			case event := <-eventRecorder.Events:
-				assert.Contains(t, event, "Unschedulable pod didn't trigger scale-up, because it's consuming ProvisioningRequest default/pr-class")
+				assert.Contains(t, event, "Unschedulable pod default/pr-pod-1 didn't trigger scale-up, because it's consuming ProvisioningRequest default/pr-class")
 			case <-time.After(1 * time.Second):
 				t.Errorf("Timeout waiting for event")
 			}
@@ -81,10 +85,53 @@ func TestProvisioningRequestPodsFilter(t *testing.T) {
 	}
 }
 
+func TestProvisioningRequestPodsFilterExternalClasses(t *testing.T) {
+	externalPod := BuildTestPod("external-pod", 500, 10)
+	externalPod.Annotations[provisioningRequestPodAnnotationKey] = "pr-external"
+	externalPod.Annotations[provisioningClassPodAnnotationKey] = "queued-provisioning"
+
+	internalPod := BuildTestPod("internal-pod", 500, 10)
+	internalPod.Annotations[provisioningRequestPodAnnotationKey] = "pr-internal"
+	internalPod.Annotations[provisioningClassPodAnnotationKey] = "check-capacity"
+
+	noClassPod := BuildTestPod("no-class-pod", 500, 10)
+	noClassPod.Annotations[provisioningRequestPodAnnotationKey] = "pr-no-class"
+
+	pod := BuildTestPod("pod", 500, 10)
+
+	eventRecorder := record.NewFakeRecorder(10)
+	ctx := &context.AutoscalingContext{AutoscalingKubeClients: context.AutoscalingKubeClients{Recorder: eventRecorder}}
+	filter := NewProvisioningRequestPodsFilter(NewDefautlEventManager(), "", []string{"queued-provisioning"})
+	got, err := filter.Process(ctx, []*apiv1.Pod{externalPod, internalPod, noClassPod, pod})
+	assert.NoError(t, err)
+	assert.ElementsMatch(t, []*apiv1.Pod{internalPod, noClassPod, pod}, got)
+	select {
+	case event := <-eventRecorder.Events:
+		assert.Contains(t, event, "consuming ProvisioningRequest default/pr-external")
+	case <-time.After(1 * time.Second):
+		t.Errorf("Timeout waiting for event")
+	}
+}
+
+func TestProvisioningRequestPodsFilterAnnotationKey(t *testing.T) {
+	customKey := "example.com/provisioning-request"
+	customPod := BuildTestPod("custom-pod", 500, 10)
+	customPod.Annotations[customKey] = "pr-custom"
+	defaultPod := BuildTestPod("default-pod", 500, 10)
+	defaultPod.Annotations[provisioningRequestPodAnnotationKey] = "pr-default"
+
+	eventRecorder := record.NewFakeRecorder(10)
+	ctx := &context.AutoscalingContext{AutoscalingKubeClients: context.AutoscalingKubeClients{Recorder: eventRecorder}}
+	filter := NewProvisioningRequestPodsFilter(NewDefautlEventManager(), customKey, nil)
+	got, err := filter.Process(ctx, []*apiv1.Pod{customPod, defaultPod})
+	assert.NoError(t, err)
+	assert.ElementsMatch(t, []*apiv1.Pod{defaultPod}, got)
+}
+
 func TestEventManager(t *testing.T) {
 	eventLimit := 5
 	eventManager := &defaultEventManager{limit: eventLimit}
-	prFilter := NewProvisioningRequestPodsFilter(eventManager)
+	prFilter := NewProvisioningRequestPodsFilter(eventManager, "", nil)
 	eventRecorder := record.NewFakeRecorder(10)
 	ctx := &context.AutoscalingContext{AutoscalingKubeClients: context.AutoscalingKubeClients{Recorder: eventRecorder}}
 	unscheduledPods := []*v1.Pod{BuildTestPod("pod", 500, 10)}
@@ -103,7 +150,7 @@ func TestEventManager(t *testing.T) {
 	for i := 0; i < eventLimit; i++ {
 		select {
 		case event := <-eventRecorder.Events:
-			assert.Contains(t, event, "Unschedulable pod didn't trigger scale-up, because it's consuming ProvisioningRequest default/pr-class")
+			assert.Contains(t, event, fmt.Sprintf("Unschedulable pod default/pr-pod-%d didn't trigger scale-up, because it's consuming ProvisioningRequest default/pr-class", i))
 		case <-time.After(1 * time.Second):
 			t.Errorf("Timeout waiting for event")
 		}
@@ -115,3 +162,54 @@ func TestEventManager(t *testing.T) {
 		return
 	}
 }
+
+func TestEventManagerWindow(t *testing.T) {
+	eventManager := &defaultEventManager{limit: 2, window: time.Minute}
+	eventRecorder := record.NewFakeRecorder(10)
+	ctx := &context.AutoscalingContext{AutoscalingKubeClients: context.AutoscalingKubeClients{Recorder: eventRecorder}}
+	pod := BuildTestPod("pr-pod", 10, 10)
+	start := time.Now()
+
+	for _, tc := range []struct {
+		offset         time.Duration
+		expectedEvents int
+	}{
+		{offset: 0, expectedEvents: 2},
+		{offset: 30 * time.Second, expectedEvents: 0},
+		{offset: time.Minute, expectedEvents: 2},
+		{offset: 90 * time.Second, expectedEvents: 0},
+	} {
+		eventManager.Reset()
+		for i := 0; i < 3; i++ {
+			eventManager.LogIgnoredInScaleUpEvent(ctx, start.Add(tc.offset), pod, "pr-class")
+		}
+		assert.Equal(t, tc.expectedEvents, len(eventRecorder.Events), "events at %v", tc.offset)
+		for len(eventRecorder.Events) > 0 {
+			<-eventRecorder.Events
+		}
+	}
+}
+
+func TestProvisioningRequestPodsFilterMetrics(t *testing.T) {
+	metrics.RegisterAll(false)
+	var unschedulablePods []*apiv1.Pod
+	for i := 0; i < 3; i++ {
+		prPod := BuildTestPod(fmt.Sprintf("pr-pod-%d", i), 10, 10)
+		prPod.Annotations[provisioningRequestPodAnnotationKey] = "pr-metrics"
+		prPod.Annotations[provisioningClassPodAnnotationKey] = "metrics-class"
+		unschedulablePods = append(unschedulablePods, prPod)
+	}
+	unschedulablePods = append(unschedulablePods, BuildTestPod("pod", 10, 10))
+
+	eventRecorder := record.NewFakeRecorder(10)
+	ctx := &context.AutoscalingContext{AutoscalingKubeClients: context.AutoscalingKubeClients{Recorder: eventRecorder}}
+	filter := NewProvisioningRequestPodsFilter(NewDefautlEventManager(), "", nil)
+	got, err := filter.Process(ctx, unschedulablePods)
+	assert.NoError(t, err)
+	assert.Len(t, got, 1)
+
+	rr := httptest.NewRecorder()
+	legacyregistry.Handler().ServeHTTP(rr, httptest.NewRequest("GET", "/", nil))
+	assert.Equal(t, http.StatusOK, rr.Code)
+	assert.Contains(t, rr.Body.String(), `cluster_autoscaler_ignored_provisioning_request_pods_total{provisioning_class="metrics-class"} 3`)
+}
@@ -0,0 +1,92 @@
+/*
+Copyright 2023 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package scaledowncandidates
+
+import (
+	"reflect"
+
+	apiv1 "k8s.io/api/core/v1"
+	"k8s.io/klog/v2"
+
+	"k8s.io/autoscaler/cluster-autoscaler/cloudprovider"
+	"k8s.io/autoscaler/cluster-autoscaler/context"
+	"k8s.io/autoscaler/cluster-autoscaler/utils/errors"
+)
+
+// ScaleDownCandidatesDeferralProcessor is a processor to filter out nodes
+// from node groups which currently defer scale down, e.g. during an upgrade.
+type ScaleDownCandidatesDeferralProcessor struct{}
+
+// NewScaleDownCandidatesDeferralProcessor returns a new ScaleDownCandidatesDeferralProcessor.
+func NewScaleDownCandidatesDeferralProcessor() *ScaleDownCandidatesDeferralProcessor {
+	return &ScaleDownCandidatesDeferralProcessor{}
+}
+
+// GetPodDestinationCandidates returns nodes as is no processing is required here
+func (p *ScaleDownCandidatesDeferralProcessor) GetPodDestinationCandidates(ctx *context.AutoscalingContext,
+	nodes []*apiv1.Node) ([]*apiv1.Node, errors.AutoscalerError) {
+	return nodes, nil
+}
+
+// GetScaleDownCandidates returns nodes from node groups which don't defer scale down.
+func (p *ScaleDownCandidatesDeferralProcessor) GetScaleDownCandidates(ctx *context.AutoscalingContext,
+	nodes []*apiv1.Node) ([]*apiv1.Node, errors.AutoscalerError) {
+	result := []*apiv1.Node{}
+	deferred := make(map[string]bool)
+
+	for _, node := range nodes {
+		nodeGroup, err := ctx.CloudProvider.NodeGroupForNode(node)
+		if err != nil {
+			klog.Warningf("Error while checking node group for %s: %v", node.Name, err)
+			continue
+		}
+		if nodeGroup == nil || reflect.ValueOf(nodeGroup).IsNil() {
+			klog.V(4).Infof("Node %s should not be processed by cluster autoscaler (no node group config)", node.Name)
+			continue
+		}
+
+		isDeferred, checked := deferred[nodeGroup.Id()]
+		if !checked {
+			isDeferred = scaleDownDeferred(ctx, nodeGroup)
+			deferred[nodeGroup.Id()] = isDeferred
+		}
+		if isDeferred {
+			continue
+		}
+
+		result = append(result, node)
+	}
+	return result, nil
+}
+
+// CleanUp is called at CA termination.
+func (p *ScaleDownCandidatesDeferralProcessor) CleanUp() {
+}
+
+func scaleDownDeferred(ctx *context.AutoscalingContext, nodeGroup cloudprovider.NodeGroup) bool {
+	deferrer, ok := nodeGroup.(cloudprovider.ScaleDownDeferrer)
+	if !ok {
+		return false
+	}
+	deferred, reason := deferrer.ScaleDownDeferred()
+	if deferred {
+		klog.V(2).Infof("Skipping scale down on node group %s: %s", nodeGroup.Id(), reason)
+		ctx.LogRecorder.Eventf(apiv1.EventTypeNormal, "ScaleDownDeferred",
+			"Scale-down of node group %s deferred: %s", nodeGroup.Id(), reason)
+	}
+	return deferred
+}
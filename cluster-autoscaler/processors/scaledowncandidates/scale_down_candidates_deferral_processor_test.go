@@ -0,0 +1,81 @@
+/*
+Copyright 2023 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package scaledowncandidates
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	v1 "k8s.io/api/core/v1"
+	"k8s.io/client-go/kubernetes/fake"
+	kube_record "k8s.io/client-go/tools/record"
+
+	testprovider "k8s.io/autoscaler/cluster-autoscaler/cloudprovider/test"
+	"k8s.io/autoscaler/cluster-autoscaler/clusterstate/utils"
+	"k8s.io/autoscaler/cluster-autoscaler/context"
+	. "k8s.io/autoscaler/cluster-autoscaler/utils/test"
+)
+
+type deferringNodeGroup struct {
+	*testprovider.TestNodeGroup
+	deferred bool
+}
+
+func (ng *deferringNodeGroup) ScaleDownDeferred() (bool, string) {
+	return ng.deferred, "upgrade in progress"
+}
+
+func TestGetScaleDownCandidatesDeferred(t *testing.T) {
+	n1 := BuildTestNode("n1", 1000, 1000)
+	n2 := BuildTestNode("n2", 1000, 1000)
+	n3 := BuildTestNode("n3", 1000, 1000)
+	n4 := BuildTestNode("n4", 1000, 1000)
+
+	provider := testprovider.NewTestCloudProvider(nil, nil)
+	provider.InsertNodeGroup(&deferringNodeGroup{TestNodeGroup: provider.BuildNodeGroup("ng-deferred", 0, 10, 2, false, "", nil), deferred: true})
+	provider.InsertNodeGroup(&deferringNodeGroup{TestNodeGroup: provider.BuildNodeGroup("ng-not-deferred", 0, 10, 1, false, "", nil), deferred: false})
+	provider.AddNodeGroup("ng-regular", 0, 10, 1)
+	provider.AddNode("ng-deferred", n1)
+	provider.AddNode("ng-deferred", n2)
+	provider.AddNode("ng-not-deferred", n3)
+	provider.AddNode("ng-regular", n4)
+
+	fakeRecorder := kube_record.NewFakeRecorder(5)
+	logRecorder, err := utils.NewStatusMapRecorder(fake.NewSimpleClientset(), "kube-system", fakeRecorder, true, "my-cool-configmap")
+	assert.NoError(t, err)
+	ctx := &context.AutoscalingContext{
+		CloudProvider: provider,
+		AutoscalingKubeClients: context.AutoscalingKubeClients{
+			LogRecorder: logRecorder,
+		},
+	}
+
+	p := NewScaleDownCandidatesDeferralProcessor()
+	candidates, err := p.GetScaleDownCandidates(ctx, []*v1.Node{n1, n2, n3, n4})
+	assert.NoError(t, err)
+	assert.Equal(t, []*v1.Node{n3, n4}, candidates)
+
+	destinations, err := p.GetPodDestinationCandidates(ctx, []*v1.Node{n1, n2, n3, n4})
+	assert.NoError(t, err)
+	assert.Equal(t, []*v1.Node{n1, n2, n3, n4}, destinations)
+
+	// A single event is emitted for the deferred node group.
+	assert.Len(t, fakeRecorder.Events, 1)
+	event := <-fakeRecorder.Events
+	assert.Contains(t, event, "ScaleDownDeferred")
+	assert.Contains(t, event, "ng-deferred")
+}
@@ -0,0 +1,138 @@
+/*
+Copyright 2023 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package status
+
+import (
+	"reflect"
+
+	apiv1 "k8s.io/api/core/v1"
+	klog "k8s.io/klog/v2"
+
+	"k8s.io/autoscaler/cluster-autoscaler/context"
+	"k8s.io/autoscaler/cluster-autoscaler/core/scaledown/status"
+	"k8s.io/autoscaler/cluster-autoscaler/metrics"
+	"k8s.io/autoscaler/cluster-autoscaler/simulator"
+	"k8s.io/autoscaler/cluster-autoscaler/utils/drain"
+)
+
+const (
+	// BlockedByPdb is the blocking reason reported for nodes with pods not having enough PDB left.
+	BlockedByPdb = "pdb"
+	// BlockedByLocalStorage is the blocking reason reported for nodes with pods using local storage.
+	BlockedByLocalStorage = "local-storage"
+	// BlockedBySystemPod is the blocking reason reported for nodes with unmovable kube-system pods.
+	BlockedBySystemPod = "system-pod"
+	// BlockedByOtherPod is the blocking reason reported for nodes with pods which can't be moved for other reasons.
+	BlockedByOtherPod = "other-pod"
+)
+
+// BlockedScaleDownStatusProcessor emits warnings for node groups which are kept
+// above their min size by idle nodes that can't be removed, for example because
+// of a PDB, local storage or a kube-system pod.
+type BlockedScaleDownStatusProcessor struct {
+	iterations int
+	// blockedIterations counts consecutive iterations in which a node group had blocked nodes.
+	blockedIterations map[string]int
+	// blockingPods remembers why a node was last found blocked, as subsequent iterations
+	// may only report it as recently unremovable.
+	blockingPods map[string]*drain.BlockingPod
+}
+
+// NewBlockedScaleDownStatusProcessor creates a BlockedScaleDownStatusProcessor emitting
+// a warning every time a node group stays blocked for the given number of iterations.
+func NewBlockedScaleDownStatusProcessor(iterations int) *BlockedScaleDownStatusProcessor {
+	return &BlockedScaleDownStatusProcessor{
+		iterations:        iterations,
+		blockedIterations: make(map[string]int),
+		blockingPods:      make(map[string]*drain.BlockingPod),
+	}
+}
+
+// Process processes the status of the cluster after a scale-down.
+func (p *BlockedScaleDownStatusProcessor) Process(context *context.AutoscalingContext, status *status.ScaleDownStatus) {
+	blockedNodes := make(map[string][]*blockedNode)
+	blockingPods := make(map[string]*drain.BlockingPod)
+	for _, unremovableNode := range status.UnremovableNodes {
+		if unremovableNode.NodeGroup == nil || reflect.ValueOf(unremovableNode.NodeGroup).IsNil() {
+			continue
+		}
+		blockingPod := unremovableNode.BlockingPod
+		if unremovableNode.Reason == simulator.RecentlyUnremovable {
+			blockingPod = p.blockingPods[unremovableNode.Node.Name]
+		} else if unremovableNode.Reason != simulator.BlockedByPod {
+			continue
+		}
+		if blockingPod == nil {
+			continue
+		}
+		blockingPods[unremovableNode.Node.Name] = blockingPod
+		nodeGroup := unremovableNode.NodeGroup
+		targetSize, err := nodeGroup.TargetSize()
+		if err != nil {
+			klog.Warningf("Failed to get target size of node group %s: %v", nodeGroup.Id(), err)
+			continue
+		}
+		if targetSize <= nodeGroup.MinSize() {
+			continue
+		}
+		blockedNodes[nodeGroup.Id()] = append(blockedNodes[nodeGroup.Id()], &blockedNode{
+			node:        unremovableNode.Node,
+			blockingPod: blockingPod,
+		})
+	}
+	p.blockingPods = blockingPods
+
+	blockedIterations := make(map[string]int, len(blockedNodes))
+	for nodeGroupId, nodes := range blockedNodes {
+		blockedIterations[nodeGroupId] = p.blockedIterations[nodeGroupId] + 1
+		if blockedIterations[nodeGroupId]%p.iterations != 0 {
+			continue
+		}
+		for _, n := range nodes {
+			reason := blockingReason(n.blockingPod)
+			klog.Warningf("Node group %s is kept above min size for %d iterations, node %s can't be removed: %s (pod %s/%s)",
+				nodeGroupId, blockedIterations[nodeGroupId], n.node.Name, reason, n.blockingPod.Pod.Namespace, n.blockingPod.Pod.Name)
+			context.Recorder.Eventf(n.node, apiv1.EventTypeWarning, "ScaleDownBlocked",
+				"node group %s is kept above min size for %d iterations, node can't be removed: %s (pod %s/%s)",
+				nodeGroupId, blockedIterations[nodeGroupId], reason, n.blockingPod.Pod.Namespace, n.blockingPod.Pod.Name)
+			metrics.RegisterBlockedScaleDownWarning(reason)
+		}
+	}
+	p.blockedIterations = blockedIterations
+}
+
+// CleanUp cleans up the processor's internal structures.
+func (p *BlockedScaleDownStatusProcessor) CleanUp() {
+}
+
+type blockedNode struct {
+	node        *apiv1.Node
+	blockingPod *drain.BlockingPod
+}
+
+func blockingReason(blockingPod *drain.BlockingPod) string {
+	switch blockingPod.Reason {
+	case drain.NotEnoughPdb:
+		return BlockedByPdb
+	case drain.LocalStorageRequested:
+		return BlockedByLocalStorage
+	case drain.UnmovableKubeSystemPod:
+		return BlockedBySystemPod
+	default:
+		return BlockedByOtherPod
+	}
+}
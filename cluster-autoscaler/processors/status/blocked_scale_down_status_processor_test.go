@@ -0,0 +1,116 @@
+/*
+Copyright 2023 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package status
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	kube_record "k8s.io/client-go/tools/record"
+
+	"k8s.io/autoscaler/cluster-autoscaler/cloudprovider"
+	cp_test "k8s.io/autoscaler/cluster-autoscaler/cloudprovider/test"
+	"k8s.io/autoscaler/cluster-autoscaler/context"
+	"k8s.io/autoscaler/cluster-autoscaler/core/scaledown/status"
+	"k8s.io/autoscaler/cluster-autoscaler/simulator"
+	"k8s.io/autoscaler/cluster-autoscaler/utils/drain"
+	. "k8s.io/autoscaler/cluster-autoscaler/utils/test"
+)
+
+func TestBlockedScaleDownStatusProcessor(t *testing.T) {
+	aboveMin := cp_test.NewTestNodeGroup("above-min", 5, 1, 3, true, false, "", nil, nil)
+	atMin := cp_test.NewTestNodeGroup("at-min", 5, 1, 1, true, false, "", nil, nil)
+	n1 := BuildTestNode("n1", 1000, 1000)
+	n2 := BuildTestNode("n2", 1000, 1000)
+	pdbBlocked := &drain.BlockingPod{Pod: BuildTestPod("p1", 100, 100), Reason: drain.NotEnoughPdb}
+
+	testCases := []struct {
+		name           string
+		nodeGroup      cloudprovider.NodeGroup
+		reasons        []simulator.UnremovableReason
+		expectedEvents int
+	}{
+		{
+			name:           "pdb blocked idle node triggers the warning",
+			nodeGroup:      aboveMin,
+			reasons:        []simulator.UnremovableReason{simulator.BlockedByPod, simulator.RecentlyUnremovable, simulator.RecentlyUnremovable},
+			expectedEvents: 1,
+		},
+		{
+			name:           "not enough iterations",
+			nodeGroup:      aboveMin,
+			reasons:        []simulator.UnremovableReason{simulator.BlockedByPod, simulator.RecentlyUnremovable},
+			expectedEvents: 0,
+		},
+		{
+			name:           "node group at min size",
+			nodeGroup:      atMin,
+			reasons:        []simulator.UnremovableReason{simulator.BlockedByPod, simulator.BlockedByPod, simulator.BlockedByPod},
+			expectedEvents: 0,
+		},
+		{
+			name:           "blocked iterations reset when node becomes removable",
+			nodeGroup:      aboveMin,
+			reasons:        []simulator.UnremovableReason{simulator.BlockedByPod, simulator.BlockedByPod, simulator.NotUnneededLongEnough, simulator.BlockedByPod},
+			expectedEvents: 0,
+		},
+		{
+			name:           "node not underutilized",
+			nodeGroup:      aboveMin,
+			reasons:        []simulator.UnremovableReason{simulator.NotUnderutilized, simulator.NotUnderutilized, simulator.NotUnderutilized},
+			expectedEvents: 0,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			fakeRecorder := kube_record.NewFakeRecorder(10)
+			ctx := &context.AutoscalingContext{
+				AutoscalingKubeClients: context.AutoscalingKubeClients{
+					Recorder: fakeRecorder,
+				},
+			}
+			p := NewBlockedScaleDownStatusProcessor(3)
+			for _, reason := range tc.reasons {
+				var blockingPod *drain.BlockingPod
+				if reason == simulator.BlockedByPod {
+					blockingPod = pdbBlocked
+				}
+				p.Process(ctx, &status.ScaleDownStatus{
+					UnremovableNodes: []*status.UnremovableNode{
+						{Node: n1, NodeGroup: tc.nodeGroup, Reason: reason, BlockingPod: blockingPod},
+						{Node: n2, NodeGroup: tc.nodeGroup, Reason: simulator.NotUnderutilized},
+					},
+				})
+			}
+			events := []string{}
+			for eventsLeft := true; eventsLeft; {
+				select {
+				case event := <-fakeRecorder.Events:
+					events = append(events, event)
+				default:
+					eventsLeft = false
+				}
+			}
+			assert.Len(t, events, tc.expectedEvents)
+			for _, event := range events {
+				assert.Contains(t, event, "ScaleDownBlocked")
+				assert.Contains(t, event, BlockedByPdb)
+			}
+		})
+	}
+}
@@ -89,6 +89,8 @@ const (
 	BlockedByPod
 	// UnexpectedError - node can't be removed because of an unexpected error.
 	UnexpectedError
+	// NodeGroupScaleDownDisabled - node can't be removed because scale down is disabled for its node group.
+	NodeGroupScaleDownDisabled
 )
 
 // RemovalSimulator is a helper object for simulating node removal scenarios.